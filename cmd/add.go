@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"go-passman/internal/models"
 	"go-passman/internal/storage"
@@ -14,25 +15,59 @@ import (
 // NewAddCommand creates the add command
 func NewAddCommand() *cobra.Command {
 	var generate bool
+	var gen generatorOptions
+	var rot rotationOptions
+	var fields fieldOptions
+	var org organizeOptions
+	var otpOpts otpOptions
+	var script scriptOptions
 
 	cmd := &cobra.Command{
-		Use:   "add",
+		Use:   "add [service]",
 		Short: "Add a new service or entry to the vault",
+		Long: "Without arguments add asks for every value. With the service name it never prompts: values come from\n" +
+			"--login, --host, --comment and the other flags, the password from --password-stdin or --generate.\n" +
+			"Exit codes: 2 invalid arguments, 4 service exists, 5 weak password (see --allow-weak),\n" +
+			"6 vault cannot be opened (set GO_PASSMAN_PASSWORD for an encrypted vault), 7 save failed.",
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if generate {
-				return handleAddGenerate()
+			err := entryFlagsFromCmd(cmd, &gen, &rot, &fields, &org, &otpOpts)
+			if len(args) == 1 {
+				if err != nil {
+					return exitStatus(cmd, scriptFail(exitUsage, "%v", err))
+				}
+				return exitStatus(cmd, handleAddScripted(args[0], &script, &gen, generate || !gen.interactive, &rot, &fields, &org, &otpOpts))
 			}
-			return handleAddManual()
+			if script.given(cmd) {
+				return fmt.Errorf("--login, --host, --comment, --password-stdin and --allow-weak need the service name as an argument")
+			}
+			if err != nil {
+				return err
+			}
+			// Any generator flag implies -g
+			if generate || !gen.interactive {
+				if err := gen.validate(); err != nil {
+					return err
+				}
+				return handleAddGenerate(&gen, &rot, &fields, &org, &otpOpts)
+			}
+			return handleAddManual(&rot, &fields, &org, &otpOpts)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&generate, "generate", "g", false, "Generate a random password")
+	addGeneratorFlags(cmd, &gen)
+	addRotationFlags(cmd, &rot)
+	addOrganizeFlags(cmd, &org, false)
+	addFieldFlags(cmd, &fields, false)
+	addOTPFlags(cmd, &otpOpts, false)
+	addScriptFlags(cmd, &script, false)
 
 	return cmd
 }
 
-func handleAddManual() error {
-	// Load vault first (if encrypted, password prompt before service name)
+func handleAddManual(rot *rotationOptions, fields *fieldOptions, org *organizeOptions, otpOpts *otpOptions) error {
+	// Load vault first (if encrypted, password prompt before service name); the lock is only taken to save
 	vault, pwd, err := storage.LoadVault()
 	if err != nil {
 		return err
@@ -67,16 +102,37 @@ func handleAddManual() error {
 	if err != nil {
 		return err
 	}
-
-	vault.Entries[service] = models.PasswordEntry{
-		Login:     login,
-		Host:      host,
-		Comment:   comment,
-		Password:  password,
-		Encrypted: false,
+	if !confirmStrength(password, service, login) {
+		fmt.Println("❌ Not saved.")
+		return nil
 	}
 
-	if err := storage.SaveVault(vault, pwd); err != nil {
+	entry := models.PasswordEntry{
+		Login:   login,
+		Host:    host,
+		Comment: comment,
+	}
+	if err := fields.edit(&entry); err != nil {
+		return err
+	}
+	if err := org.edit(&entry); err != nil {
+		return err
+	}
+	if err := otpOpts.apply(&entry); err != nil {
+		return err
+	}
+	now := time.Now()
+	entry.SetPassword(password, now)
+	entry.Touch(now)
+	rot.apply(&entry)
+	vault, err = changeVault(pwd, func(vault *models.Vault) error {
+		if _, exists := vault.Entries[service]; exists {
+			return fmt.Errorf("%w: '%s' was added. Not saved", errConflict, service)
+		}
+		vault.Entries[service] = entry
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
@@ -87,8 +143,8 @@ func handleAddManual() error {
 	return nil
 }
 
-func handleAddGenerate() error {
-	// Load vault first (if encrypted, password prompt before service name)
+func handleAddGenerate(gen *generatorOptions, rot *rotationOptions, fields *fieldOptions, org *organizeOptions, otpOpts *otpOptions) error {
+	// Load vault first (if encrypted, password prompt before service name); the lock is only taken to save
 	vault, pwd, err := storage.LoadVault()
 	if err != nil {
 		return err
@@ -119,28 +175,52 @@ func handleAddGenerate() error {
 		return err
 	}
 
-	// Get password generation options
-	length, useNumbers, useSpecial := utils.ChoosePasswordOptions()
-	password := utils.GeneratePassword(length, useNumbers, useSpecial)
-
-	vault.Entries[service] = models.PasswordEntry{
-		Login:     login,
-		Host:      host,
-		Comment:   comment,
-		Password:  password,
-		Encrypted: false,
+	// Get password generation options (flags, or ask)
+	password, entropy, err := gen.generate()
+	if err != nil {
+		return err
+	}
+	if !confirmStrength(password, service, login) {
+		fmt.Println("❌ Not saved.")
+		return nil
 	}
 
-	if err := storage.SaveVault(vault, pwd); err != nil {
+	entry := models.PasswordEntry{
+		Login:   login,
+		Host:    host,
+		Comment: comment,
+	}
+	if err := fields.edit(&entry); err != nil {
+		return err
+	}
+	if err := org.edit(&entry); err != nil {
+		return err
+	}
+	if err := otpOpts.apply(&entry); err != nil {
+		return err
+	}
+	now := time.Now()
+	entry.SetPassword(password, now)
+	entry.Touch(now)
+	rot.apply(&entry)
+	vault, err = changeVault(pwd, func(vault *models.Vault) error {
+		if _, exists := vault.Entries[service]; exists {
+			return fmt.Errorf("%w: '%s' was added. Not saved", errConflict, service)
+		}
+		vault.Entries[service] = entry
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
 	// Copy to clipboard
-	if err := utils.CopyToClipboard(password); err != nil {
+	if err := copySecret(password, &gen.clear); err != nil {
 		fmt.Printf("⚠️  Password saved but clipboard copy failed: %v\n", err)
 	} else {
-		fmt.Printf("✅ Password for '%s' saved and copied to clipboard.\n", service)
+		fmt.Printf("✅ Password for '%s' saved and copied to clipboard%s.\n", service, gen.clear.note())
 	}
+	printEntropy(entropy)
 	if !vault.Encrypted && len(vault.Entries) == 1 {
 		fmt.Println("💡 Tip: run 'go-passman encrypt' to protect your vault with a master password.")
 	}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go-passman/internal/audit"
+	"go-passman/internal/storage"
+	"go-passman/internal/utils"
+)
+
+// NewAuditCommand creates the audit command group
+func NewAuditCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Check the passwords in the vault for problems",
+		Long:  "Audit reports are computed locally and never print passwords.",
+	}
+
+	cmd.AddCommand(newAuditWeakCommand(), newAuditReuseCommand(), newAuditBreachedCommand())
+
+	return cmd
+}
+
+func newAuditWeakCommand() *cobra.Command {
+	var minScore int
+
+	cmd := &cobra.Command{
+		Use:   "weak",
+		Short: "List entries with weak passwords",
+		Long: "Estimate the strength of every password (dictionary words, common passwords, keyboard patterns,\n" +
+			"sequences, repeats, years, the service name and login) and list those scoring below --min-score.\n" +
+			"Scores: 0 very weak, 1 weak, 2 fair, 3 strong, 4 very strong.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if minScore < utils.ScoreWeak || minScore > utils.ScoreVeryStrong {
+				return fmt.Errorf("min score must be between %d and %d", utils.ScoreWeak, utils.ScoreVeryStrong)
+			}
+			return handleAuditWeak(minScore)
+		},
+	}
+
+	cmd.Flags().IntVarP(&minScore, "min-score", "m", audit.DefaultMinScore, "Report passwords scoring below this (1-4)")
+
+	return cmd
+}
+
+func handleAuditWeak(minScore int) error {
+	vault, _, err := storage.LoadVault()
+	if err != nil {
+		return err
+	}
+
+	if len(vault.Entries) == 0 {
+		fmt.Println("📭 No passwords saved yet.")
+		return nil
+	}
+
+	weak := audit.Weak(vault.Entries, minScore)
+	if len(weak) == 0 {
+		fmt.Printf("✅ All %d passwords are at least %s.\n", len(vault.Entries), utils.ScoreLabel(minScore))
+		return nil
+	}
+
+	numbers := make(map[string]int)
+	for i, s := range getSortedServices(vault.Entries) {
+		numbers[s] = i + 1
+	}
+
+	fmt.Printf("⚠️  %d of %d passwords are weaker than %s:\n", len(weak), len(vault.Entries), utils.ScoreLabel(minScore))
+	for _, w := range weak {
+		fmt.Printf("  %d. %s — %s (~%.0f bits)", numbers[w.Service], w.Service, w.Strength.Label(), w.Strength.Bits)
+		if len(w.Strength.Warnings) > 0 {
+			fmt.Printf(": %s", strings.Join(w.Strength.Warnings, ", "))
+		}
+		fmt.Println()
+	}
+	fmt.Println("💡 Use 'update -g' to replace them with generated passwords.")
+	return nil
+}
+
+func newAuditReuseCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reuse",
+		Short: "List entries that share a password",
+		Long: "Group entries whose passwords are identical, or differ only by trailing digits (summer1 / summer2).\n" +
+			"Passwords are never printed.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleAuditReuse()
+		},
+	}
+}
+
+func handleAuditReuse() error {
+	vault, _, err := storage.LoadVault()
+	if err != nil {
+		return err
+	}
+
+	if len(vault.Entries) == 0 {
+		fmt.Println("📭 No passwords saved yet.")
+		return nil
+	}
+
+	groups := audit.Reuse(vault.Entries)
+	if len(groups) == 0 {
+		fmt.Printf("✅ No reused passwords in %d entries.\n", len(vault.Entries))
+		return nil
+	}
+
+	numbers := make(map[string]int)
+	for i, s := range getSortedServices(vault.Entries) {
+		numbers[s] = i + 1
+	}
+
+	fmt.Printf("⚠️  %d groups of entries share a password:\n", len(groups))
+	for _, g := range groups {
+		names := make([]string, len(g.Services))
+		for i, s := range g.Services {
+			names[i] = fmt.Sprintf("%d. %s", numbers[s], s)
+		}
+		kind := "Same password"
+		if !g.Exact {
+			kind = "Similar (differ only by trailing digits)"
+		}
+		fmt.Printf("  %s: %s\n", kind, strings.Join(names, ", "))
+	}
+	fmt.Println("💡 Use 'update -g' to give each entry its own password.")
+	return nil
+}
+
+func newAuditBreachedCommand() *cobra.Command {
+	var hibpFile string
+
+	cmd := &cobra.Command{
+		Use:   "breached",
+		Short: "Check passwords against a downloaded Have I Been Pwned hash list (offline)",
+		Long: "Look up the SHA-1 hash of every password in a local copy of the Have I Been Pwned password list.\n" +
+			"--hibp-file is either the file ordered by hash (lines HASH:COUNT, searched in place, so multi-GB files\n" +
+			"are fine) or a directory of range files from the HIBP downloader (ABCDE.txt with SUFFIX:COUNT lines).\n" +
+			"Nothing is sent over the network and passwords are never printed.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleAuditBreached(hibpFile)
+		},
+	}
+
+	cmd.Flags().StringVar(&hibpFile, "hibp-file", "", "Ordered SHA-1 hash file or range-file directory")
+	cmd.MarkFlagRequired("hibp-file")
+
+	return cmd
+}
+
+func handleAuditBreached(hibpFile string) error {
+	hibp, err := audit.OpenHIBP(hibpFile)
+	if err != nil {
+		return err
+	}
+	defer hibp.Close()
+
+	vault, _, err := storage.LoadVault()
+	if err != nil {
+		return err
+	}
+
+	if len(vault.Entries) == 0 {
+		fmt.Println("📭 No passwords saved yet.")
+		return nil
+	}
+
+	hits, err := audit.Breached(vault.Entries, hibp)
+	if err != nil {
+		return err
+	}
+	if len(hits) == 0 {
+		fmt.Printf("✅ None of %d passwords were found in the breach list.\n", len(vault.Entries))
+		return nil
+	}
+
+	numbers := make(map[string]int)
+	for i, s := range getSortedServices(vault.Entries) {
+		numbers[s] = i + 1
+	}
+
+	fmt.Printf("⚠️  %d of %d passwords appear in known breaches:\n", len(hits), len(vault.Entries))
+	for _, h := range hits {
+		fmt.Printf("  %d. %s — seen %d times\n", numbers[h.Service], h.Service, h.Count)
+	}
+	fmt.Println("💡 Change these passwords; use 'update -g' to generate new ones.")
+	return nil
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"go-passman/internal/storage"
+	"go-passman/internal/utils"
+)
+
+// NewBackupCommand creates the backup command group
+func NewBackupCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "List or restore automatic vault backups",
+		Long: "Every save keeps the previous vault as vault.json.bak.1 (older versions move to .bak.2, .bak.3, ...).\n" +
+			"Use 'backup list' to see them and 'backup restore N' to bring one back.",
+	}
+
+	cmd.AddCommand(newBackupListCommand(), newBackupRestoreCommand())
+
+	return cmd
+}
+
+func newBackupListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List vault backups (1 = newest)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleBackupList()
+		},
+	}
+}
+
+func newBackupRestoreCommand() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "restore N",
+		Short: "Replace the vault with backup N (the current vault becomes backup 1)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid backup number %q", args[0])
+			}
+			return handleBackupRestore(n, yes)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation")
+
+	return cmd
+}
+
+func handleBackupList() error {
+	backups, err := storage.ListBackups()
+	if err != nil {
+		return err
+	}
+
+	if len(backups) == 0 {
+		fmt.Println("📭 No backups yet. A backup is created every time the vault is saved.")
+		return nil
+	}
+
+	fmt.Println("🗂  Vault backups (restore N):")
+	fmt.Println()
+	for _, b := range backups {
+		contents := fmt.Sprintf("%d entries", b.Entries)
+		if b.Encrypted {
+			contents = "encrypted"
+		}
+		fmt.Printf("  %d.  %s · %s · %d bytes\n", b.Index, b.ModTime.Format("2006-01-02 15:04:05"), contents, b.Size)
+	}
+	fmt.Println()
+	return nil
+}
+
+func handleBackupRestore(n int, yes bool) error {
+	if !yes && !utils.ConfirmAction(fmt.Sprintf("Replace the current vault with backup %d?", n)) {
+		fmt.Println("❌ Operation cancelled.")
+		return nil
+	}
+
+	if err := storage.RestoreBackup(n); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Vault restored from backup %d. The previous vault was saved as backup 1.\n", n)
+	return nil
+}
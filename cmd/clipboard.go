@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go-passman/internal/utils"
+)
+
+// clearClipboardUse is the hidden command that clears the clipboard in the background.
+const clearClipboardUse = "clear-clipboard"
+
+// clearOptions holds the clipboard clearing flags of commands that copy secrets.
+type clearOptions struct {
+	noClear bool
+	after   time.Duration
+}
+
+// addClearFlags registers --no-clear and --clear-after on cmd.
+func addClearFlags(cmd *cobra.Command, opts *clearOptions) {
+	cmd.Flags().BoolVar(&opts.noClear, "no-clear", false, "Leave the copied value on the clipboard")
+	cmd.Flags().DurationVar(&opts.after, "clear-after", utils.DefaultClearAfter, "Clear the clipboard after this long if it still holds the copied value")
+}
+
+// enabled reports whether the clipboard will be cleared.
+func (o *clearOptions) enabled() bool {
+	return !o.noClear && o.after > 0
+}
+
+// note is appended to "copied" messages, e.g. " (cleared in 45s)".
+func (o *clearOptions) note() string {
+	if !o.enabled() {
+		return ""
+	}
+	return fmt.Sprintf(" (cleared in %s)", o.after)
+}
+
+// copySecret copies text to the clipboard and starts the helper that clears it later.
+// A helper that cannot be started only warns: the value was copied.
+func copySecret(text string, opts *clearOptions) error {
+	if err := utils.CopyToClipboard(text); err != nil {
+		return err
+	}
+	if opts.enabled() {
+		if err := utils.StartClipboardClear(text, clearClipboardUse, "--after", opts.after.String()); err != nil {
+			fmt.Printf("⚠️  The clipboard will not be cleared automatically: %v\n", err)
+			opts.noClear = true
+		}
+	}
+	return nil
+}
+
+// NewClearClipboardCommand creates the hidden helper started by copySecret. It reads the digest of the
+// copied value from stdin, waits, and clears the clipboard only if it still holds that value.
+func NewClearClipboardCommand() *cobra.Command {
+	var after time.Duration
+
+	cmd := &cobra.Command{
+		Use:         clearClipboardUse,
+		Hidden:      true,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{skipStorageInit: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			digest, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil {
+				return fmt.Errorf("failed to read the clipboard digest: %w", err)
+			}
+			time.Sleep(after)
+			_, err = utils.ClearClipboardIf(digest)
+			return err
+		},
+	}
+
+	cmd.Flags().DurationVar(&after, "after", utils.DefaultClearAfter, "Wait this long before clearing")
+
+	return cmd
+}
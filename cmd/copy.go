@@ -3,34 +3,44 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
+	"go-passman/internal/models"
 	"go-passman/internal/storage"
-	"go-passman/internal/utils"
 
 	"github.com/spf13/cobra"
 )
 
 // NewCopyCommand creates the copy command
 func NewCopyCommand() *cobra.Command {
+	var clr clearOptions
+
 	cmd := &cobra.Command{
-		Use:   "copy [service|N]",
-		Short: "Copy the password to the clipboard (by name or by number from list)",
-		Args:  cobra.ExactArgs(1),
+		Use:   "copy [service|N|search]",
+		Short: "Copy the password to the clipboard (by name, by number from list, or by search)",
+		Long: "Copy the password of an entry to the clipboard. The argument is a service name or a number from list;\n" +
+			"the start of exactly one name is taken as that name. Anything else is searched like list --filter\n" +
+			"(e.g. gthb or \"host:prod login:admin\"): a single match is copied after you confirm it, several are\n" +
+			"offered to choose from. When input is not a terminal only names, numbers and unique starts work.",
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return handleCopy(args[0])
+			return handleCopy(args[0], &clr)
 		},
 	}
 
+	addClearFlags(cmd, &clr)
+
 	return cmd
 }
 
-func handleCopy(serviceOrNum string) error {
-	vault, _, err := storage.LoadVault()
+func handleCopy(serviceOrNum string, clr *clearOptions) error {
+	// Read without the lock (choosing an entry may wait for input); the last-used time is saved under it afterwards
+	vault, pwd, err := storage.LoadVault()
 	if err != nil {
 		return err
 	}
 
-	service, err := resolveServiceOrNumber(vault.Entries, serviceOrNum)
+	service, err := findService(vault.Entries, serviceOrNum)
 	if err != nil {
 		fmt.Printf("❌ %v\n", err)
 		os.Exit(1)
@@ -38,7 +48,7 @@ func handleCopy(serviceOrNum string) error {
 
 	entry := vault.Entries[service]
 
-	if err := utils.CopyToClipboard(entry.Password); err != nil {
+	if err := copySecret(entry.Password, clr); err != nil {
 		return err
 	}
 
@@ -51,8 +61,44 @@ func handleCopy(serviceOrNum string) error {
 	if entry.Comment != "" {
 		fmt.Printf("Comment for '%s': %s\n", service, entry.Comment)
 	}
+	for _, f := range entry.Fields {
+		fmt.Printf("%s for '%s': %s\n", f.Name, service, fieldValue(f))
+	}
 
-	fmt.Printf("📋 Password for '%s' copied to clipboard!\n", service)
+	fmt.Printf("📋 Password for '%s' copied to clipboard%s!\n", service, clr.note())
+	touchAccess(pwd, service)
 
 	return nil
 }
+
+// touchAccess records the last use of service for commands that loaded the vault without the lock: the vault
+// is read again under the lock, so changes other processes made in the meantime are kept. Failing only warns.
+func touchAccess(pwd *string, service string) {
+	err := storage.WithLock(func() error {
+		vault, _, err := storage.LoadVaultWithPassword(pwd)
+		if err != nil {
+			return err
+		}
+		entry, exists := vault.Entries[service]
+		if !exists {
+			return nil
+		}
+		entry.AccessedAt = time.Now()
+		vault.Entries[service] = entry
+		return storage.SaveVaultAccess(vault, pwd)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not record the last use of '%s': %v\n", service, err)
+	}
+}
+
+// recordAccess saves the last-used time of service without rotating backups.
+// Failing to save only warns: the password was already handed out.
+func recordAccess(vault *models.Vault, pwd *string, service string) {
+	entry := vault.Entries[service]
+	entry.AccessedAt = time.Now()
+	vault.Entries[service] = entry
+	if err := storage.SaveVaultAccess(vault, pwd); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not record the last use of '%s': %v\n", service, err)
+	}
+}
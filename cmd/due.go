@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go-passman/internal/audit"
+	"go-passman/internal/models"
+	"go-passman/internal/storage"
+)
+
+// Exit codes of 'due' for cron checks (1 is any other error)
+const (
+	exitDueSoon    = 2
+	exitDueExpired = 3
+)
+
+// NewDueCommand creates the due command
+func NewDueCommand() *cobra.Command {
+	var withinDays int
+	var quiet bool
+
+	cmd := &cobra.Command{
+		Use:   "due",
+		Short: "List passwords that are expired or must be rotated soon",
+		Long: "Entries can have a rotation interval (add/update --rotate DAYS, counted from the last password change)\n" +
+			"and/or a fixed expiry date (--expires YYYY-MM-DD). 'due' lists the expired ones and those due within\n" +
+			"--within days.\n\n" +
+			"Exit code: 0 = nothing due, 2 = passwords due soon, 3 = passwords expired, 1 = error.\n" +
+			"For cron use --quiet: nothing is printed when nothing is due.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if withinDays < 0 {
+				return fmt.Errorf("--within cannot be negative")
+			}
+			return exitStatus(cmd, handleDue(time.Duration(withinDays)*24*time.Hour, quiet))
+		},
+	}
+
+	cmd.Flags().IntVarP(&withinDays, "within", "d", int(audit.DefaultDueWithin/(24*time.Hour)), "Also list passwords due within this many days")
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Print nothing when nothing is due")
+
+	return cmd
+}
+
+func handleDue(within time.Duration, quiet bool) error {
+	vault, _, err := storage.LoadVault()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	due := audit.Due(vault.Entries, now, within)
+	if len(due) == 0 {
+		if !quiet {
+			fmt.Printf("✅ No passwords expired or due within %d days.\n", int(within.Hours()/24))
+		}
+		return nil
+	}
+
+	numbers := make(map[string]int)
+	for i, s := range getSortedServices(vault.Entries) {
+		numbers[s] = i + 1
+	}
+
+	expired, _ := audit.CountDue(due)
+	fmt.Printf("⏰ %d passwords to rotate:\n", len(due))
+	for _, d := range due {
+		fmt.Printf("  %d. %s — %s\n", numbers[d.Service], d.Service, describeDue(d, now))
+	}
+	fmt.Println("💡 Use 'update -g' to set a new password; the rotation interval restarts from the change.")
+
+	if expired > 0 {
+		return &ExitError{Code: exitDueExpired}
+	}
+	return &ExitError{Code: exitDueSoon}
+}
+
+// describeDue says when a password expired or is due, e.g. "expired 3 days ago (2026-01-02)".
+func describeDue(d audit.DueEntry, now time.Time) string {
+	if d.DueAt.IsZero() {
+		return "due now (last password change unknown)"
+	}
+	days := int(d.DueAt.Sub(now).Hours() / 24)
+	date := d.DueAt.Local().Format("2006-01-02")
+	switch {
+	case d.Expired && days == 0:
+		return fmt.Sprintf("expired today (%s)", date)
+	case d.Expired:
+		return fmt.Sprintf("expired %d days ago (%s)", -days, date)
+	case days == 0:
+		return fmt.Sprintf("due today (%s)", date)
+	}
+	return fmt.Sprintf("due in %d days (%s)", days, date)
+}
+
+// printDueWarning prints a one-line reminder when passwords are expired or due soon (used by status).
+func printDueWarning(entries map[string]models.PasswordEntry) {
+	expired, soon := audit.CountDue(audit.Due(entries, time.Now(), audit.DefaultDueWithin))
+	if expired+soon == 0 {
+		return
+	}
+	var parts []string
+	if expired > 0 {
+		parts = append(parts, fmt.Sprintf("%d expired", expired))
+	}
+	if soon > 0 {
+		parts = append(parts, fmt.Sprintf("%d due within %d days", soon, int(audit.DefaultDueWithin.Hours()/24)))
+	}
+	fmt.Printf("⚠️  Password rotation: %s — run 'go-passman due'.\n", strings.Join(parts, ", "))
+}
+
+// rotationOptions holds the --rotate and --expires flags of add and update.
+type rotationOptions struct {
+	days    int
+	expires string
+
+	setDays, setExpires bool
+	expiresAt           time.Time
+}
+
+// addRotationFlags registers the rotation flags on cmd.
+func addRotationFlags(cmd *cobra.Command, opts *rotationOptions) {
+	cmd.Flags().IntVar(&opts.days, "rotate", 0, "Rotate the password every N days (0 = no rotation)")
+	cmd.Flags().StringVar(&opts.expires, "expires", "", "Password expiry date YYYY-MM-DD ('never' to clear)")
+}
+
+// fromFlags reads and validates the rotation flags given on cmd.
+func (o *rotationOptions) fromFlags(cmd *cobra.Command) error {
+	o.setDays = cmd.Flags().Changed("rotate")
+	o.setExpires = cmd.Flags().Changed("expires")
+	if o.days < 0 {
+		return fmt.Errorf("--rotate cannot be negative")
+	}
+	if o.setExpires && o.expires != "never" && o.expires != "" {
+		t, err := time.ParseInLocation("2006-01-02", o.expires, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --expires date %q (use YYYY-MM-DD)", o.expires)
+		}
+		o.expiresAt = t
+	}
+	return nil
+}
+
+// given reports whether --rotate or --expires was used.
+func (o *rotationOptions) given() bool {
+	return o.setDays || o.setExpires
+}
+
+// apply sets the given rotation flags on entry.
+func (o *rotationOptions) apply(entry *models.PasswordEntry) {
+	if o.setDays {
+		entry.RotationDays = o.days
+	}
+	if o.setExpires {
+		entry.ExpiresAt = o.expiresAt
+	}
+}
@@ -35,10 +35,11 @@ func NewDecryptCommand() *cobra.Command {
 }
 
 func handleEncrypt() error {
-	vault, _, err := storage.LoadVault()
+	vault, _, err := storage.LoadVaultForUpdate()
 	if err != nil {
 		return err
 	}
+	defer storage.Unlock()
 
 	if vault.Encrypted {
 		fmt.Println("ℹ️  Vault is already encrypted.")
@@ -57,6 +58,15 @@ func handleEncrypt() error {
 	}
 
 	fmt.Println("✅ Vault encrypted successfully.")
+
+	// Backups from an earlier encryption may use another password; keep only those that open with this one
+	removed, err := storage.ReencryptBackups(password, password)
+	if err != nil {
+		return fmt.Errorf("failed to check backups: %w", err)
+	}
+	if removed > 0 {
+		fmt.Printf("🗑  Removed %d backup(s) encrypted with another password.\n", removed)
+	}
 	return nil
 }
 
@@ -71,10 +81,11 @@ func handleDecrypt() error {
 		return nil
 	}
 
-	vault, _, err := storage.LoadVault()
+	vault, _, err := storage.LoadVaultForUpdate()
 	if err != nil {
 		return err
 	}
+	defer storage.Unlock()
 
 	vault.Encrypted = false
 	if err := storage.SaveVault(vault, nil); err != nil {
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go-passman/internal/models"
+	"go-passman/internal/utils"
+)
+
+// fieldOptions holds the custom field flags of add and update.
+type fieldOptions struct {
+	plain  []string // NAME=VALUE
+	secret []string // NAME or NAME=VALUE; without a value it is asked for with hidden input
+	remove []string // NAME (update only)
+}
+
+// addFieldFlags registers the custom field flags on cmd; --remove-field only where fields can exist already.
+func addFieldFlags(cmd *cobra.Command, opts *fieldOptions, withRemove bool) {
+	cmd.Flags().StringArrayVar(&opts.plain, "field", nil, "Set a plain custom field NAME=VALUE (repeatable)")
+	cmd.Flags().StringArrayVar(&opts.secret, "secret", nil, "Set a concealed custom field NAME (value asked, hidden) or NAME=VALUE (repeatable)")
+	if withRemove {
+		cmd.Flags().StringArrayVar(&opts.remove, "remove-field", nil, "Remove the custom field NAME (repeatable)")
+	}
+}
+
+// given reports whether any field flag was used; then the field prompts are skipped.
+func (o *fieldOptions) given() bool {
+	return len(o.plain)+len(o.secret)+len(o.remove) > 0
+}
+
+// needsInput reports whether apply would ask for a value (--secret NAME without =VALUE).
+func (o *fieldOptions) needsInput() bool {
+	for _, f := range o.secret {
+		if !strings.Contains(f, "=") {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks the flag values before any prompt.
+func (o *fieldOptions) validate() error {
+	for _, f := range o.plain {
+		if name, _, ok := strings.Cut(f, "="); !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid --field %q (use NAME=VALUE)", f)
+		}
+	}
+	for _, f := range o.secret {
+		if name, _, _ := strings.Cut(f, "="); strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid --secret %q (use NAME or NAME=VALUE)", f)
+		}
+	}
+	return nil
+}
+
+// apply sets the fields from the flags on entry, asking for secret values that were not given.
+func (o *fieldOptions) apply(entry *models.PasswordEntry) error {
+	for _, name := range o.remove {
+		if !entry.RemoveField(name) {
+			fmt.Printf("⚠️  No custom field '%s' to remove.\n", name)
+		}
+	}
+	for _, f := range o.plain {
+		name, value, _ := strings.Cut(f, "=")
+		entry.SetField(strings.TrimSpace(name), value, false)
+	}
+	for _, f := range o.secret {
+		name, value, hasValue := strings.Cut(f, "=")
+		name = strings.TrimSpace(name)
+		if !hasValue {
+			var err error
+			if value, err = utils.ReadPassword(fmt.Sprintf("Value for '%s' (hidden): ", name)); err != nil {
+				return err
+			}
+		}
+		entry.SetField(name, value, true)
+	}
+	return nil
+}
+
+// edit applies the field flags, or asks for the fields when no flag was given.
+func (o *fieldOptions) edit(entry *models.PasswordEntry) error {
+	if o.given() {
+		return o.apply(entry)
+	}
+	return promptFields(entry)
+}
+
+// promptFields lets the user change or remove the existing custom fields and add new ones.
+func promptFields(entry *models.PasswordEntry) error {
+	for _, f := range append([]models.CustomField(nil), entry.Fields...) {
+		prompt := fmt.Sprintf("%s [%s] (Enter = keep, - = remove): ", f.Name, fieldValue(f))
+		read := utils.ReadInput
+		if f.Concealed {
+			read = utils.ReadPassword
+		}
+		value, err := read(prompt)
+		if err != nil {
+			return err
+		}
+		switch value {
+		case "":
+		case "-":
+			entry.RemoveField(f.Name)
+		default:
+			entry.SetField(f.Name, value, f.Concealed)
+		}
+	}
+
+	for {
+		name, err := utils.ReadInput("Add custom field name (optional, press Enter to skip): ")
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			return nil
+		}
+		concealed := utils.ConfirmAction(fmt.Sprintf("Conceal '%s' like a password?", name))
+		read := utils.ReadInput
+		if concealed {
+			read = utils.ReadPassword
+		}
+		value, err := read(fmt.Sprintf("Value for '%s': ", name))
+		if err != nil {
+			return err
+		}
+		entry.SetField(name, value, concealed)
+	}
+}
+
+// fieldValue returns the value to display: concealed fields are masked like the password.
+func fieldValue(f models.CustomField) string {
+	if f.Concealed {
+		return "****"
+	}
+	return f.Value
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// NewGenerateCommand creates the generate command
+func NewGenerateCommand() *cobra.Command {
+	var gen generatorOptions
+	var count int
+	var toStdout bool
+
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate a password or passphrase without saving it",
+		Long: "Generate a strong password (or a passphrase with --passphrase) without touching the vault.\n" +
+			"In a terminal the password is copied to the clipboard; when output is piped it is printed to stdout.",
+		Args: cobra.NoArgs,
+		// Never reads or writes the vault
+		Annotations: map[string]string{skipStorageInit: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			gen.fromFlags(cmd)
+			// Defaults instead of prompts when no option is given
+			gen.interactive = false
+			return handleGenerate(&gen, count, toStdout)
+		},
+	}
+
+	addGeneratorFlags(cmd, &gen)
+	cmd.Flags().IntVarP(&count, "count", "c", 1, "Number of passwords to generate (more than one is always printed)")
+	cmd.Flags().BoolVarP(&toStdout, "print", "p", false, "Print to stdout instead of copying to the clipboard")
+
+	return cmd
+}
+
+func handleGenerate(gen *generatorOptions, count int, toStdout bool) error {
+	if count < 1 {
+		return fmt.Errorf("count must be at least 1")
+	}
+	if err := gen.validate(); err != nil {
+		return err
+	}
+
+	interactive := term.IsTerminal(int(os.Stdout.Fd()))
+	if !interactive || toStdout || count > 1 {
+		var entropy float64
+		for i := 0; i < count; i++ {
+			password, bits, err := gen.generate()
+			if err != nil {
+				return err
+			}
+			entropy = bits
+			fmt.Println(password)
+		}
+		if interactive {
+			printEntropy(entropy)
+		}
+		return nil
+	}
+
+	password, entropy, err := gen.generate()
+	if err != nil {
+		return err
+	}
+	if err := copySecret(password, &gen.clear); err != nil {
+		fmt.Printf("⚠️  Clipboard copy failed (%v); use --print to show the password.\n", err)
+		return err
+	}
+	fmt.Printf("📋 Generated password copied to clipboard%s.\n", gen.clear.note())
+	printEntropy(entropy)
+	return nil
+}
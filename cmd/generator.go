@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go-passman/internal/audit"
+	"go-passman/internal/utils"
+)
+
+// generatorOptions holds the password generator flags shared by add -g and update -g.
+type generatorOptions struct {
+	length           int
+	noLower          bool
+	noUpper          bool
+	noNumbers        bool
+	noSpecial        bool
+	excludeAmbiguous bool
+	charset          string
+	minPerClass      int
+
+	passphrase bool
+	words      int
+	separator  string
+	capitalize bool
+	digit      bool
+
+	interactive bool // no generator flag given: ask with utils.ChoosePasswordOptions
+
+	clear clearOptions // clearing the clipboard after copying the generated password
+}
+
+// generatorFlagNames lists the flags that select non-interactive generation.
+var generatorFlagNames = []string{
+	"length", "no-lower", "no-upper", "no-numbers", "no-special", "exclude-ambiguous", "charset", "min-per-class",
+	"passphrase", "words", "separator", "capitalize", "digit",
+}
+
+// addGeneratorFlags registers the password generator flags on cmd.
+func addGeneratorFlags(cmd *cobra.Command, opts *generatorOptions) {
+	def := utils.DefaultPasswordPolicy()
+	cmd.Flags().IntVarP(&opts.length, "length", "l", def.Length, "Generated password length")
+	cmd.Flags().BoolVar(&opts.noLower, "no-lower", false, "Generate without lowercase letters")
+	cmd.Flags().BoolVar(&opts.noUpper, "no-upper", false, "Generate without uppercase letters")
+	cmd.Flags().BoolVar(&opts.noNumbers, "no-numbers", false, "Generate without digits")
+	cmd.Flags().BoolVar(&opts.noSpecial, "no-special", false, "Generate without special characters")
+	cmd.Flags().BoolVar(&opts.excludeAmbiguous, "exclude-ambiguous", false, "Do not use easily confused characters (0 O 1 l I |)")
+	cmd.Flags().StringVar(&opts.charset, "charset", "", "Generate only from these characters (overrides the class options)")
+	cmd.Flags().IntVar(&opts.minPerClass, "min-per-class", def.MinPerClass, "Minimum characters from each selected class")
+
+	defPhrase := utils.DefaultPassphrasePolicy()
+	cmd.Flags().BoolVar(&opts.passphrase, "passphrase", false, "Generate a diceware passphrase (words) instead of random characters")
+	cmd.Flags().IntVar(&opts.words, "words", defPhrase.Words, "Passphrase word count")
+	cmd.Flags().StringVar(&opts.separator, "separator", defPhrase.Separator, "Passphrase word separator")
+	cmd.Flags().BoolVar(&opts.capitalize, "capitalize", false, "Capitalize each passphrase word")
+	cmd.Flags().BoolVar(&opts.digit, "digit", false, "Add a random digit to one passphrase word")
+
+	addClearFlags(cmd, &opts.clear)
+}
+
+// fromFlags reports whether any generator flag was given on cmd; otherwise the options are asked interactively.
+func (o *generatorOptions) fromFlags(cmd *cobra.Command) {
+	o.interactive = true
+	for _, name := range generatorFlagNames {
+		if cmd.Flags().Changed(name) {
+			o.interactive = false
+			break
+		}
+	}
+	// Passphrase options only make sense in passphrase mode
+	if cmd.Flags().Changed("words") || cmd.Flags().Changed("separator") || cmd.Flags().Changed("capitalize") || cmd.Flags().Changed("digit") {
+		o.passphrase = true
+	}
+}
+
+// policy returns the password policy from the flags, or asks for it with utils.ChoosePasswordOptions
+// when no generator flag was given.
+func (o *generatorOptions) policy() utils.PasswordPolicy {
+	if o.interactive {
+		return utils.ChoosePasswordOptions()
+	}
+	return utils.PasswordPolicy{
+		Length:           o.length,
+		Lower:            !o.noLower,
+		Upper:            !o.noUpper,
+		Numbers:          !o.noNumbers,
+		Special:          !o.noSpecial,
+		ExcludeAmbiguous: o.excludeAmbiguous,
+		Charset:          o.charset,
+		MinPerClass:      o.minPerClass,
+	}
+}
+
+// passphrasePolicy returns the passphrase policy from the flags.
+func (o *generatorOptions) passphrasePolicy() utils.PassphrasePolicy {
+	return utils.PassphrasePolicy{
+		Words:      o.words,
+		Separator:  o.separator,
+		Capitalize: o.capitalize,
+		Digit:      o.digit,
+	}
+}
+
+// validate checks the flag values before any prompt, so bad options fail fast.
+func (o *generatorOptions) validate() error {
+	if o.interactive {
+		return nil
+	}
+	if o.passphrase {
+		return o.passphrasePolicy().Validate()
+	}
+	return o.policy().Validate()
+}
+
+// generate creates a password or passphrase and returns it with its entropy in bits.
+func (o *generatorOptions) generate() (string, float64, error) {
+	if o.passphrase {
+		p := o.passphrasePolicy()
+		phrase, err := utils.GeneratePassphrase(p)
+		return phrase, p.Entropy(), err
+	}
+	p := o.policy()
+	password, err := utils.GeneratePassword(p)
+	return password, p.Entropy(), err
+}
+
+// printEntropy prints the strength of a generated password.
+func printEntropy(bits float64) {
+	fmt.Printf("🔒 Entropy: ~%.0f bits\n", bits)
+}
+
+// confirmStrength warns when password is weak and asks whether to save it anyway.
+// The service name and login count as guessable words.
+func confirmStrength(password, service, login string) bool {
+	s := utils.EstimateStrength(password, service, login)
+	if s.Score >= audit.DefaultMinScore {
+		return true
+	}
+	fmt.Printf("⚠️  This password is %s (~%.0f bits):\n", s.Label(), s.Bits)
+	for _, w := range s.Warnings {
+		fmt.Printf("    - %s\n", w)
+	}
+	return utils.ConfirmAction("Save it anyway?")
+}
@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go-passman/internal/models"
+	"go-passman/internal/otp"
+	"go-passman/internal/storage"
+	"golang.org/x/term"
+)
+
+// getFields are the entry fields get --field knows besides custom fields (which they take precedence over).
+var getFields = []string{"service", "login", "host", "comment", "password", "folder", "tags", "otp"}
+
+// getOptions holds the flags of the get command.
+type getOptions struct {
+	fields []string
+	json   bool
+	reveal bool
+}
+
+// entryRecord is the exported form of an entry (get --json, list --output). Secrets are empty unless revealed.
+type entryRecord struct {
+	Service           string        `json:"service"`
+	Login             string        `json:"login"`
+	Host              string        `json:"host"`
+	Comment           string        `json:"comment"`
+	Folder            string        `json:"folder"`
+	Tags              []string      `json:"tags"`
+	Password          string        `json:"password,omitempty"`
+	Fields            []fieldRecord `json:"fields"`
+	OTP               *otpRecord    `json:"otp,omitempty"`
+	CreatedAt         string        `json:"created_at,omitempty"`
+	ModifiedAt        string        `json:"modified_at,omitempty"`
+	PasswordChangedAt string        `json:"password_changed_at,omitempty"`
+	AccessedAt        string        `json:"accessed_at,omitempty"`
+	DueAt             string        `json:"due_at,omitempty"` // password rotation or expiry date
+}
+
+// fieldRecord is the exported form of a custom field; the value of a concealed field is empty unless revealed.
+type fieldRecord struct {
+	Name      string `json:"name"`
+	Value     string `json:"value,omitempty"`
+	Concealed bool   `json:"concealed"`
+}
+
+// otpRecord describes an entry's one-time password secret without the secret itself.
+type otpRecord struct {
+	Type  string `json:"type"`
+	Label string `json:"label"`
+	Code  string `json:"code,omitempty"` // current TOTP code, when revealed
+}
+
+// newEntryRecord builds the exported form of entry, with its secrets when reveal is set.
+func newEntryRecord(service string, entry models.PasswordEntry, reveal bool) entryRecord {
+	rec := entryRecord{
+		Service:           service,
+		Login:             entry.Login,
+		Host:              entry.Host,
+		Comment:           entry.Comment,
+		Folder:            entry.Folder,
+		Tags:              append([]string{}, entry.Tags...),
+		Fields:            []fieldRecord{},
+		CreatedAt:         isoTime(entry.CreatedAt),
+		ModifiedAt:        isoTime(entry.ModifiedAt),
+		PasswordChangedAt: isoTime(entry.PasswordChangedAt),
+		AccessedAt:        isoTime(entry.AccessedAt),
+	}
+	if due, ok := entry.DueAt(); ok {
+		rec.DueAt = isoTime(due)
+	}
+	if reveal {
+		rec.Password = entry.Password
+	}
+	for _, f := range entry.Fields {
+		fr := fieldRecord{Name: f.Name, Concealed: f.Concealed}
+		if reveal || !f.Concealed {
+			fr.Value = f.Value
+		}
+		rec.Fields = append(rec.Fields, fr)
+	}
+	if entry.OTP != nil {
+		rec.OTP = &otpRecord{Type: entry.OTP.Type, Label: otp.Label(*entry.OTP)}
+		if reveal && entry.OTP.Type == otp.TypeTOTP {
+			rec.OTP.Code, _, _ = otp.TOTP(*entry.OTP, time.Now())
+		}
+	}
+	return rec
+}
+
+// isoTime formats t as RFC 3339, or "" when unknown.
+func isoTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// NewGetCommand creates the get command
+func NewGetCommand() *cobra.Command {
+	var opts getOptions
+
+	cmd := &cobra.Command{
+		Use:     "get [service|N]",
+		Aliases: []string{"show"},
+		Short:   "Print the fields of an entry to stdout",
+		Long: "Print an entry: login, host, comment, folder, tags, password, custom fields and one-time password.\n" +
+			"In a terminal the password and concealed fields are masked unless --reveal is given; when output is\n" +
+			"piped they are printed as they are, e.g. PW=$(go-passman get db -f password).\n\n" +
+			"--field NAME prints only that value (" + strings.Join(getFields, ", ") + " or a custom field;\n" +
+			"otp is the current TOTP code). An encrypted vault is opened with GO_PASSMAN_PASSWORD when set; the\n" +
+			"password prompt goes to stderr. Exit codes: 3 no such entry or field, 6 vault cannot be opened.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !opts.reveal && !term.IsTerminal(int(os.Stdout.Fd())) {
+				opts.reveal = true
+			}
+			return exitStatus(cmd, handleGet(args[0], &opts))
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&opts.fields, "field", "f", nil, "Print only this field (repeatable: one value per line)")
+	cmd.Flags().BoolVar(&opts.json, "json", false, "Print as JSON")
+	cmd.Flags().BoolVar(&opts.reveal, "reveal", false, "Show the password and concealed fields in a terminal")
+
+	return cmd
+}
+
+func handleGet(serviceOrNum string, opts *getOptions) error {
+	vault, pwd, err := storage.LoadVault()
+	if err != nil {
+		return scriptFail(exitVault, "%v", err)
+	}
+
+	service, err := resolveServiceOrNumber(vault.Entries, serviceOrNum)
+	if err != nil {
+		return scriptFail(exitNotFound, "%v", err)
+	}
+	entry := vault.Entries[service]
+
+	if len(opts.fields) > 0 {
+		values := make(map[string]string, len(opts.fields))
+		for _, name := range opts.fields {
+			value, err := getField(service, entry, name, opts.reveal)
+			if err != nil {
+				return err
+			}
+			values[name] = value
+		}
+		if opts.json {
+			if err := printJSON(values); err != nil {
+				return err
+			}
+		} else {
+			for _, name := range opts.fields {
+				fmt.Println(values[name])
+			}
+		}
+	} else if opts.json {
+		if err := printJSON(newEntryRecord(service, entry, opts.reveal)); err != nil {
+			return err
+		}
+	} else {
+		printEntry(newEntryRecord(service, entry, opts.reveal))
+	}
+
+	if opts.reveal {
+		touchAccess(pwd, service)
+	}
+	return nil
+}
+
+// getField returns the value of one field of entry; it fails when the entry has no such field.
+func getField(service string, entry models.PasswordEntry, name string, reveal bool) (string, error) {
+	mask := func(s string) string {
+		if reveal || s == "" {
+			return s
+		}
+		return "****"
+	}
+	switch strings.ToLower(name) {
+	case "service":
+		return service, nil
+	case "login":
+		return entry.Login, nil
+	case "host":
+		return entry.Host, nil
+	case "comment":
+		return entry.Comment, nil
+	case "password":
+		return mask(entry.Password), nil
+	case "folder":
+		return entry.Folder, nil
+	case "tags":
+		return strings.Join(entry.Tags, ","), nil
+	case "otp":
+		if entry.OTP == nil || entry.OTP.Type != otp.TypeTOTP {
+			return "", scriptFail(exitNotFound, "'%s' has no TOTP secret (use 'hotp' for counter-based codes)", service)
+		}
+		code, _, err := otp.TOTP(*entry.OTP, time.Now())
+		if err != nil {
+			return "", scriptFail(exitNotFound, "failed to generate the code for '%s': %v", service, err)
+		}
+		return mask(code), nil
+	}
+	f := entry.Field(name)
+	if f == nil {
+		if strings.TrimSpace(name) == "" {
+			return "", scriptFail(exitUsage, "empty --field name")
+		}
+		return "", scriptFail(exitNotFound, "'%s' has no field '%s' (fields: %s or a custom field)", service, name, strings.Join(getFields, ", "))
+	}
+	if f.Concealed {
+		return mask(f.Value), nil
+	}
+	return f.Value, nil
+}
+
+// printEntry prints an entry record for reading; masked secrets show as ****.
+func printEntry(rec entryRecord) {
+	secret := func(s string) string {
+		if s == "" {
+			return "****"
+		}
+		return s
+	}
+	fmt.Printf("Service:  %s\n", rec.Service)
+	fmt.Printf("Login:    %s\n", orEmpty(rec.Login))
+	fmt.Printf("Host:     %s\n", orEmpty(rec.Host))
+	fmt.Printf("Comment:  %s\n", orEmpty(rec.Comment))
+	fmt.Printf("Folder:   %s\n", orEmpty(rec.Folder))
+	fmt.Printf("Tags:     %s\n", orEmpty(strings.Join(rec.Tags, ", ")))
+	fmt.Printf("Password: %s\n", secret(rec.Password))
+	for _, f := range rec.Fields {
+		if f.Concealed {
+			fmt.Printf("%s: %s\n", f.Name, secret(f.Value))
+		} else {
+			fmt.Printf("%s: %s\n", f.Name, f.Value)
+		}
+	}
+	if rec.OTP != nil {
+		if rec.OTP.Code != "" {
+			fmt.Printf("One-time password: %s, code %s\n", rec.OTP.Label, rec.OTP.Code)
+		} else {
+			fmt.Printf("One-time password: %s\n", rec.OTP.Label)
+		}
+	}
+}
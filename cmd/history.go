@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go-passman/internal/models"
+	"go-passman/internal/storage"
+	"go-passman/internal/utils"
+)
+
+// NewHistoryCommand creates the history command
+func NewHistoryCommand() *cobra.Command {
+	var copyN, restoreN int
+	var yes bool
+	var clr clearOptions
+
+	cmd := &cobra.Command{
+		Use:   "history [service|N]",
+		Short: "Show previous passwords of an entry; copy or restore one",
+		Long: "When a password is changed (update, web edit) the old one is kept with the time it was replaced\n" +
+			"(up to 10 per entry, newest first). Passwords are masked in the list; use --copy N to copy\n" +
+			"history item N to the clipboard, or --restore N to make it the current password again.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if copyN != 0 && restoreN != 0 {
+				return fmt.Errorf("use either --copy or --restore")
+			}
+			if restoreN != 0 {
+				return handleHistoryRestore(args[0], restoreN, yes)
+			}
+			return handleHistory(args[0], copyN, &clr)
+		},
+	}
+
+	cmd.Flags().IntVarP(&copyN, "copy", "c", 0, "Copy history item N to the clipboard")
+	cmd.Flags().IntVarP(&restoreN, "restore", "r", 0, "Make history item N the current password")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation when restoring")
+	addClearFlags(cmd, &clr)
+
+	return cmd
+}
+
+func handleHistory(serviceOrNum string, copyN int, clr *clearOptions) error {
+	// Copying saves the last-used time, so it locks the vault like a change
+	load := storage.LoadVault
+	if copyN != 0 {
+		load = storage.LoadVaultForUpdate
+		defer storage.Unlock()
+	}
+	vault, pwd, err := load()
+	if err != nil {
+		return err
+	}
+
+	service, err := resolveServiceOrNumber(vault.Entries, serviceOrNum)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	entry := vault.Entries[service]
+
+	if copyN != 0 {
+		if copyN < 1 || copyN > len(entry.History) {
+			fmt.Printf("❌ History item %d not found for '%s' (%d items).\n", copyN, service, len(entry.History))
+			os.Exit(1)
+		}
+		if err := copySecret(entry.History[copyN-1].Password, clr); err != nil {
+			return err
+		}
+		fmt.Printf("📋 Password %d from the history of '%s' copied to clipboard%s!\n", copyN, service, clr.note())
+		recordAccess(vault, pwd, service)
+		return nil
+	}
+
+	if len(entry.History) == 0 {
+		fmt.Printf("📭 No previous passwords for '%s'.\n", service)
+		return nil
+	}
+
+	fmt.Printf("🕘 Previous passwords for '%s' (newest first):\n", service)
+	for i, h := range entry.History {
+		fmt.Printf("  %d. ********  replaced %s\n", i+1, h.ChangedAt.Local().Format("2006-01-02 15:04"))
+	}
+	fmt.Printf("💡 Copy one with 'history %s --copy N', restore with 'history %s --restore N'.\n", serviceOrNum, serviceOrNum)
+	return nil
+}
+
+func handleHistoryRestore(serviceOrNum string, n int, yes bool) error {
+	// The lock is only taken to save, not while waiting for the confirmation
+	vault, pwd, err := storage.LoadVault()
+	if err != nil {
+		return err
+	}
+
+	service, err := resolveServiceOrNumber(vault.Entries, serviceOrNum)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	before := vault.Entries[service]
+
+	if n < 1 || n > len(before.History) {
+		fmt.Printf("❌ History item %d not found for '%s' (%d items).\n", n, service, len(before.History))
+		os.Exit(1)
+	}
+
+	replaced := before.History[n-1].ChangedAt.Local().Format("2006-01-02 15:04")
+	if !yes && !utils.ConfirmAction(fmt.Sprintf("Restore the password of '%s' replaced %s? The current one is kept in history.", service, replaced)) {
+		fmt.Println("❌ Operation cancelled.")
+		return nil
+	}
+
+	_, err = changeVault(pwd, func(vault *models.Vault) error {
+		entry, exists := vault.Entries[service]
+		if !exists {
+			return fmt.Errorf("%w: '%s' was removed. Not restored", errConflict, service)
+		}
+		if !entry.ModifiedAt.Equal(before.ModifiedAt) {
+			return fmt.Errorf("%w: '%s' was changed. Not restored", errConflict, service)
+		}
+		now := time.Now()
+		if err := entry.RestorePassword(n-1, now); err != nil {
+			return err
+		}
+		entry.Touch(now)
+		vault.Entries[service] = entry
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Password of '%s' restored from %s.\n", service, replaced)
+	return nil
+}
@@ -6,9 +6,11 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"go-passman/internal/models"
+	"go-passman/internal/search"
 	"go-passman/internal/storage"
 	"go-passman/internal/utils"
 	"golang.org/x/term"
@@ -20,26 +22,75 @@ const (
 	maxLoginLen   = 14
 	maxHostLen    = 20
 	maxCommentLen = 22
+	maxFolderLen  = 14
+	maxTagsLen    = 18
+	dateLen       = 10 // YYYY-MM-DD
 	sep            = " · "
 
 	listPageSize = 20 // entries per page when listing (paginated if total > listPageSize)
 )
 
+// listOptions holds the flags of the list command.
+type listOptions struct {
+	table   bool
+	filter  string
+	sortBy  string
+	reverse bool
+	tags    []string
+	folder  string
+	output  string
+	reveal  bool
+	rank    bool // order by search relevance (--filter without --sort)
+}
+
+// listRecord is an entry of list --output json/yaml: its number in the list and the exported entry.
+type listRecord struct {
+	Number int `json:"number"`
+	entryRecord
+}
+
+// listColumns is the header of list --output csv/tsv (tags and field names are comma-separated).
+var listColumns = []string{"number", "service", "login", "host", "comment", "folder", "tags", "fields", "otp",
+	"created_at", "modified_at", "password_changed_at", "accessed_at", "due_at", "password"}
+
 // NewListCommand creates the list command
 func NewListCommand() *cobra.Command {
-	var table bool
-	var filter string
+	var opts listOptions
 
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all services or entries in the vault",
+		Long: "List the entries of the vault, numbered as copy N and update N expect.\n\n" +
+			"--filter searches the name, login, host, comment, tags, folder and custom fields (not secrets).\n" +
+			"All words must match; name, login and host also match fuzzily (\"gthb\" finds github).\n" +
+			"  host:prod login:admin   search one field (name, login, host, comment, tag, folder, field)\n" +
+			"  -test or !test          exclude entries containing test (also -tag:old)\n" +
+			"  \"old server\"            a phrase with spaces\n" +
+			"Results are listed best match first unless --sort is given.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return handleList(table, filter)
+			if _, ok := (models.PasswordEntry{}).Time(opts.sortBy); !ok && opts.sortBy != "name" {
+				return fmt.Errorf("unknown sort %q (use name, %s)", opts.sortBy, strings.Join(models.TimeFields, ", "))
+			}
+			if err := checkOutput(opts.output); err != nil {
+				return err
+			}
+			if opts.reveal && opts.output == outputText {
+				return fmt.Errorf("--reveal needs --output json, yaml, csv or tsv")
+			}
+			opts.tags = splitList(opts.tags)
+			opts.rank = strings.TrimSpace(opts.filter) != "" && !cmd.Flags().Changed("sort")
+			return handleList(opts)
 		},
 	}
 
-	cmd.Flags().BoolVarP(&table, "table", "t", false, "Show as table (wide; use when terminal is wide enough)")
-	cmd.Flags().StringVarP(&filter, "filter", "f", "", "Show only entries whose name contains this substring (case-insensitive)")
+	cmd.Flags().BoolVarP(&opts.table, "table", "t", false, "Show as table (wide; use when terminal is wide enough)")
+	cmd.Flags().StringVarP(&opts.filter, "filter", "f", "", "Search all fields, best matches first (fuzzy; e.g. \"host:prod tag:db -old\", see 'list --help')")
+	cmd.Flags().StringVarP(&opts.sortBy, "sort", "s", "name", "Sort by name, created, modified, changed (password) or used (times: newest first)")
+	cmd.Flags().BoolVarP(&opts.reverse, "reverse", "r", false, "Reverse the sort order")
+	cmd.Flags().StringArrayVar(&opts.tags, "tag", nil, "Show only entries with this tag (repeatable: all must match)")
+	cmd.Flags().StringVar(&opts.folder, "folder", "", "Show only entries in this folder or its subfolders")
+	addOutputFlag(cmd, &opts.output)
+	cmd.Flags().BoolVar(&opts.reveal, "reveal", false, "Include passwords and concealed fields in --output")
 
 	return cmd
 }
@@ -77,13 +128,74 @@ func resolveServiceOrNumber(entries map[string]models.PasswordEntry, input strin
 	return "", fmt.Errorf("service '%s' not found", name)
 }
 
-func handleList(useTable bool, filter string) error {
+// searchPrompt is the filter prompt of the interactive pickers (update, remove).
+const searchPrompt = "Search (e.g. git, host:prod -tag:old; Enter = all): "
+
+// searchFilter returns a ChooseFromList filter that ranks the entries with the search engine.
+func searchFilter(entries map[string]models.PasswordEntry) func(string) []string {
+	return func(query string) []string {
+		return search.Names(search.Parse(query).Rank(entries))
+	}
+}
+
+// findService resolves a name or list number like resolveServiceOrNumber, then a name that is the only one
+// starting with input (case-insensitive). Anything else is searched over all fields, but a search match is
+// never used without asking: in a terminal a single match must be confirmed and several are offered to
+// choose from; otherwise it is an error, so a typo cannot hand out the wrong secret.
+func findService(entries map[string]models.PasswordEntry, input string) (string, error) {
+	service, err := resolveServiceOrNumber(entries, input)
+	if err == nil {
+		return service, nil
+	}
+	if name, ok := uniquePrefix(entries, input); ok {
+		fmt.Printf("🔎 '%s' matches '%s'.\n", input, name)
+		return name, nil
+	}
+	matches := search.Names(search.Parse(input).Rank(entries))
+	switch {
+	case len(matches) == 0:
+		return "", err
+	case !term.IsTerminal(int(os.Stdin.Fd())):
+		return "", fmt.Errorf("'%s' is not a name or the start of one name; it matches %s. Give the name",
+			input, strings.Join(matches, ", "))
+	case len(matches) == 1:
+		if !utils.ConfirmAction(fmt.Sprintf("🔎 '%s' is not a name, but matches '%s'. Use it?", input, matches[0])) {
+			return "", utils.ErrCancelled
+		}
+		return matches[0], nil
+	}
+	return utils.ChooseFromList(matches, fmt.Sprintf("'%s' matches %d entries, best first:", input, len(matches)), "", nil)
+}
+
+// uniquePrefix returns the entry name equal to input or the only one starting with it (case-insensitive).
+func uniquePrefix(entries map[string]models.PasswordEntry, input string) (string, bool) {
+	prefix := strings.ToLower(strings.TrimSpace(input))
+	if prefix == "" {
+		return "", false
+	}
+	found := ""
+	for name := range entries {
+		lower := strings.ToLower(name)
+		if lower == prefix {
+			return name, true
+		}
+		if strings.HasPrefix(lower, prefix) {
+			if found != "" {
+				return "", false
+			}
+			found = name
+		}
+	}
+	return found, found != ""
+}
+
+func handleList(opts listOptions) error {
 	vault, _, err := storage.LoadVault()
 	if err != nil {
 		return err
 	}
 
-	if len(vault.Entries) == 0 {
+	if len(vault.Entries) == 0 && opts.output == outputText {
 		fmt.Println("📭 No passwords saved yet.")
 		return nil
 	}
@@ -96,30 +208,39 @@ func handleList(useTable bool, filter string) error {
 
 	services := allServices
 	var numbers []int // 1-based display numbers; when set, use for "copy N" consistency (e.g. with filter)
-	if filter != "" {
-		sub := strings.TrimSpace(strings.ToLower(filter))
+	query := search.Parse(opts.filter)
+	if !query.Empty() || len(opts.tags) > 0 || opts.folder != "" {
 		filtered := make([]string, 0)
 		numFiltered := make([]int, 0)
 		for i, s := range allServices {
-			if strings.Contains(strings.ToLower(s), sub) {
+			if query.Matches(s, vault.Entries[s]) && matchesOrganize(vault.Entries[s], opts.tags, opts.folder) {
 				filtered = append(filtered, s)
 				numFiltered = append(numFiltered, i+1)
 			}
 		}
 		services = filtered
 		numbers = numFiltered
-		if len(services) == 0 {
-			fmt.Printf("📭 No entries match filter %q.\n", filter)
+		if len(services) == 0 && opts.output == outputText {
+			fmt.Println("📭 No entries match the filter.")
 			return nil
 		}
 	}
 
+	if opts.rank {
+		services, numbers = rankServices(services, numbers, vault.Entries, query, opts.reverse)
+	} else if opts.sortBy != "name" || opts.reverse {
+		services, numbers = sortServices(services, numbers, vault.Entries, opts.sortBy, opts.reverse)
+	}
+	if opts.output != outputText {
+		return writeListOutput(opts, services, numbers, vault.Entries)
+	}
+
 	total := len(services)
 	totalInVault := len(allServices)
 	stdoutTTY := term.IsTerminal(int(os.Stdout.Fd()))
 	if total <= listPageSize || !stdoutTTY {
 		// No pagination: small list or output redirected (e.g. list > file)
-		if useTable {
+		if opts.table {
 			printListTableWithNumbers(services, numbers, vault.Entries, total, totalInVault)
 		} else {
 			printListCompactWithNumbers(services, numbers, vault.Entries, total, totalInVault)
@@ -144,7 +265,7 @@ func handleList(useTable bool, filter string) error {
 		totalPages := (total + listPageSize - 1) / listPageSize
 		fmt.Printf("🔐 Saved entries (page %d of %d). Найдено %d из %d\n", pageNum, totalPages, total, totalInVault)
 		fmt.Println()
-		if useTable {
+		if opts.table {
 			printListTablePageWithNumbers(page, pageNumbers, vault.Entries)
 		} else {
 			printListCompactPageWithNumbers(page, pageNumbers, vault.Entries)
@@ -159,6 +280,93 @@ func handleList(useTable bool, filter string) error {
 	return nil
 }
 
+// writeListOutput prints the listed entries in a machine-readable format, without pagination.
+func writeListOutput(opts listOptions, services []string, numbers []int, entries map[string]models.PasswordEntry) error {
+	records := make([]listRecord, 0, len(services))
+	rows := make([][]string, 0, len(services))
+	for i, service := range services {
+		num := i + 1
+		if numbers != nil {
+			num = numbers[i]
+		}
+		rec := newEntryRecord(service, entries[service], opts.reveal)
+		records = append(records, listRecord{Number: num, entryRecord: rec})
+
+		fieldNames := make([]string, len(rec.Fields))
+		for j, f := range rec.Fields {
+			fieldNames[j] = f.Name
+		}
+		otpType := ""
+		if rec.OTP != nil {
+			otpType = rec.OTP.Type
+		}
+		rows = append(rows, []string{strconv.Itoa(num), service, rec.Login, rec.Host, rec.Comment, rec.Folder,
+			strings.Join(rec.Tags, ","), strings.Join(fieldNames, ","), otpType,
+			rec.CreatedAt, rec.ModifiedAt, rec.PasswordChangedAt, rec.AccessedAt, rec.DueAt, rec.Password})
+	}
+	return writeOutput(opts.output, records, listColumns, rows)
+}
+
+// rankServices orders matching services best match first (by name on equal scores), optionally reversed.
+// numbers are reordered with them.
+func rankServices(services []string, numbers []int, entries map[string]models.PasswordEntry, query search.Query, reverse bool) ([]string, []int) {
+	scores := make(map[string]int, len(services))
+	for _, s := range services {
+		scores[s], _ = query.Score(s, entries[s])
+	}
+	idx := make([]int, len(services))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		return scores[services[idx[a]]] > scores[services[idx[b]]]
+	})
+	if reverse {
+		for i, j := 0, len(idx)-1; i < j; i, j = i+1, j-1 {
+			idx[i], idx[j] = idx[j], idx[i]
+		}
+	}
+	ranked := make([]string, len(services))
+	rankedNumbers := make([]int, len(services))
+	for i, k := range idx {
+		ranked[i] = services[k]
+		rankedNumbers[i] = k + 1
+		if numbers != nil {
+			rankedNumbers[i] = numbers[k]
+		}
+	}
+	return ranked, rankedNumbers
+}
+
+// sortServices orders services by a timestamp (newest first, unknown last) or by name, optionally reversed.
+// The returned numbers keep each entry's number from the full name-sorted list, so "copy N" still works.
+func sortServices(services []string, numbers []int, entries map[string]models.PasswordEntry, sortBy string, reverse bool) ([]string, []int) {
+	idx := make([]int, len(services))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		ta, _ := entries[services[idx[a]]].Time(sortBy)
+		tb, _ := entries[services[idx[b]]].Time(sortBy)
+		return ta.After(tb)
+	})
+	if reverse {
+		for i, j := 0, len(idx)-1; i < j; i, j = i+1, j-1 {
+			idx[i], idx[j] = idx[j], idx[i]
+		}
+	}
+	sorted := make([]string, len(services))
+	sortedNumbers := make([]int, len(services))
+	for i, k := range idx {
+		sorted[i] = services[k]
+		sortedNumbers[i] = k + 1
+		if numbers != nil {
+			sortedNumbers[i] = numbers[k]
+		}
+	}
+	return sorted, sortedNumbers
+}
+
 // printListCompact prints one short line per entry with number. Fits narrow terminals; lines may wrap but don't slide.
 func printListCompact(services []string, entries map[string]models.PasswordEntry) {
 	n := len(services)
@@ -214,11 +422,15 @@ func printListTableWithNumbers(services []string, numbers []int, entries map[str
 
 // printListTablePageWithNumbers prints one page of table; numbers[i] is the display number (nil = 1,2,...).
 func printListTablePageWithNumbers(services []string, numbers []int, entries map[string]models.PasswordEntry) {
-	widths := [5]int{4, maxServiceLen, maxLoginLen, maxHostLen, maxCommentLen}
-	headers := [5]string{"#", "Service", "Login", "Host", "Comment"}
+	widths := []int{4, maxServiceLen, maxLoginLen, maxHostLen, maxCommentLen, maxFolderLen, maxTagsLen, dateLen, dateLen, dateLen, dateLen}
+	headers := []string{"#", "Service", "Login", "Host", "Comment", "Folder", "Tags", "Created", "Modified", "Pw changed", "Last used"}
 
-	fmt.Println("  " + tableRow5(headers, widths))
-	fmt.Println("  " + strings.Repeat("-", widths[0]+widths[1]+widths[2]+widths[3]+widths[4]+8))
+	width := 2 * (len(widths) - 1)
+	for _, w := range widths {
+		width += w
+	}
+	fmt.Println("  " + tableRow(headers, widths))
+	fmt.Println("  " + strings.Repeat("-", width))
 
 	for i, service := range services {
 		num := i + 1
@@ -226,21 +438,35 @@ func printListTablePageWithNumbers(services []string, numbers []int, entries map
 			num = numbers[i]
 		}
 		entry := entries[service]
-		row := [5]string{
+		row := []string{
 			fmt.Sprintf("%d", num),
 			truncate(service, widths[1]),
 			truncate(entry.Login, widths[2]),
 			truncate(entry.Host, widths[3]),
 			truncate(entry.Comment, widths[4]),
+			entry.Folder,
+			strings.Join(entry.Tags, ","),
+			formatDate(entry.CreatedAt),
+			formatDate(entry.ModifiedAt),
+			formatDate(entry.PasswordChangedAt),
+			formatDate(entry.AccessedAt),
 		}
-		fmt.Println("  " + tableRow5(row, widths))
+		fmt.Println("  " + tableRow(row, widths))
 	}
 	fmt.Println()
 }
 
-func tableRow5(fields [5]string, widths [5]int) string {
+// formatDate shows a timestamp as YYYY-MM-DD, or "-" when unknown.
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02")
+}
+
+func tableRow(fields []string, widths []int) string {
 	var b strings.Builder
-	for i := 0; i < 5; i++ {
+	for i := range fields {
 		if i > 0 {
 			b.WriteString("  ")
 		}
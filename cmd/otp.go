@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go-passman/internal/models"
+	"go-passman/internal/otp"
+	"go-passman/internal/storage"
+	"go-passman/internal/utils"
+	"golang.org/x/term"
+)
+
+// otpOptions holds the one-time password flags of add and update.
+type otpOptions struct {
+	value  string // otpauth:// URI or base32 secret; "-" = ask with hidden input
+	remove bool   // update only
+	key    models.OTPKey
+}
+
+// addOTPFlags registers the OTP flags on cmd; --remove-otp only where a secret can exist already.
+func addOTPFlags(cmd *cobra.Command, opts *otpOptions, withRemove bool) {
+	cmd.Flags().StringVar(&opts.value, "otp", "", "Store a one-time password secret: otpauth:// URI or base32 secret (\"-\" = ask, hidden)")
+	if withRemove {
+		cmd.Flags().BoolVar(&opts.remove, "remove-otp", false, "Remove the one-time password secret")
+	}
+}
+
+// given reports whether the secret is set or removed.
+func (o *otpOptions) given() bool {
+	return o.value != "" || o.remove
+}
+
+// validate parses a secret given on the command line before any prompt.
+func (o *otpOptions) validate() error {
+	if o.value != "" && o.remove {
+		return fmt.Errorf("--otp and --remove-otp cannot be used together")
+	}
+	if o.value == "" || o.value == "-" {
+		return nil
+	}
+	key, err := otp.Parse(o.value)
+	if err != nil {
+		return err
+	}
+	o.key = key
+	return nil
+}
+
+// apply sets or removes the entry's secret, asking for it when --otp - was given.
+func (o *otpOptions) apply(entry *models.PasswordEntry) error {
+	switch {
+	case o.remove:
+		entry.OTP = nil
+	case o.value == "-":
+		value, err := utils.ReadPassword("otpauth:// URI or base32 secret (hidden): ")
+		if err != nil {
+			return err
+		}
+		key, err := otp.Parse(value)
+		if err != nil {
+			return err
+		}
+		entry.OTP = &key
+	case o.value != "":
+		key := o.key
+		entry.OTP = &key
+	}
+	return nil
+}
+
+// NewTOTPCommand creates the totp command
+func NewTOTPCommand() *cobra.Command {
+	var toStdout bool
+	var clr clearOptions
+
+	cmd := &cobra.Command{
+		Use:   "totp [service|N]",
+		Short: "Show the current one-time code of an entry and copy it to the clipboard",
+		Long: "Show the current TOTP code of an entry (set with add/update --otp) and the seconds it stays valid.\n" +
+			"In a terminal the code is also copied to the clipboard; when output is piped only the code is printed.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleTOTP(args[0], toStdout, &clr)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&toStdout, "print", "p", false, "Only print the code, do not copy it")
+	addClearFlags(cmd, &clr)
+
+	return cmd
+}
+
+func handleTOTP(serviceOrNum string, toStdout bool, clr *clearOptions) error {
+	// Read without the lock; the last-used time is saved under it afterwards
+	vault, pwd, err := storage.LoadVault()
+	if err != nil {
+		return err
+	}
+
+	service, err := resolveServiceOrNumber(vault.Entries, serviceOrNum)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	entry := vault.Entries[service]
+	if entry.OTP == nil {
+		fmt.Printf("❌ '%s' has no one-time password secret. Add one with 'update --otp'.\n", service)
+		os.Exit(1)
+	}
+	if entry.OTP.Type != otp.TypeTOTP {
+		fmt.Printf("❌ '%s' has a %s secret, not TOTP. Use '%s'.\n", service, strings.ToUpper(entry.OTP.Type), entry.OTP.Type)
+		os.Exit(1)
+	}
+
+	code, remaining, err := otp.TOTP(*entry.OTP, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to generate the code for '%s': %w", service, err)
+	}
+
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		fmt.Println(code)
+	} else {
+		fmt.Printf("🔢 %s  (valid for %ds more)\n", code, int(remaining.Seconds()))
+		if !toStdout {
+			if err := copySecret(code, clr); err != nil {
+				fmt.Printf("⚠️  Clipboard copy failed: %v\n", err)
+			} else {
+				fmt.Printf("📋 Code for '%s' copied to clipboard%s.\n", service, clr.note())
+			}
+		}
+	}
+	touchAccess(pwd, service)
+	return nil
+}
+
+// NewHOTPCommand creates the hotp command
+func NewHOTPCommand() *cobra.Command {
+	var toStdout bool
+	var clr clearOptions
+	var resync []string
+	var window int
+
+	cmd := &cobra.Command{
+		Use:   "hotp [service|N]",
+		Short: "Show the next counter-based one-time code of an entry and advance its counter",
+		Long: "Show the next HOTP code of an entry (set with add/update --otp otpauth://hotp/...) and save the advanced\n" +
+			"counter, so a code is never handed out twice. In a terminal the code is also copied to the clipboard.\n\n" +
+			"If the device and the vault got out of step, pass one or two consecutive codes from the device with\n" +
+			"--resync: the next --window counters are searched and the vault continues after the matched codes.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if window < 1 {
+				return fmt.Errorf("window must be at least 1")
+			}
+			return handleHOTP(args[0], toStdout, splitList(resync), window, &clr)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&toStdout, "print", "p", false, "Only print the code, do not copy it")
+	addClearFlags(cmd, &clr)
+	cmd.Flags().StringArrayVar(&resync, "resync", nil, "Resynchronize the counter with code(s) shown by the device (repeatable or comma-separated, consecutive)")
+	cmd.Flags().IntVar(&window, "window", otp.DefaultResyncWindow, "How many counters ahead --resync searches")
+
+	return cmd
+}
+
+func handleHOTP(serviceOrNum string, toStdout bool, resync []string, window int, clr *clearOptions) error {
+	vault, pwd, err := storage.LoadVaultForUpdate()
+	if err != nil {
+		return err
+	}
+	defer storage.Unlock()
+
+	service, err := resolveServiceOrNumber(vault.Entries, serviceOrNum)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	entry := vault.Entries[service]
+	if entry.OTP == nil || entry.OTP.Type != otp.TypeHOTP {
+		fmt.Printf("❌ '%s' has no HOTP secret. Add one with 'update --otp otpauth://hotp/...'.\n", service)
+		os.Exit(1)
+	}
+	key := *entry.OTP
+	now := time.Now()
+
+	if len(resync) > 0 {
+		next, ok, err := otp.Resync(key, resync, window)
+		if err != nil {
+			return fmt.Errorf("failed to resynchronize '%s': %w", service, err)
+		}
+		if !ok {
+			fmt.Printf("❌ The code(s) do not match counters %d to %d. Check them or widen --window.\n", key.Counter, key.Counter+uint64(window)-1)
+			os.Exit(1)
+		}
+		key.Counter = next
+		entry.OTP = &key
+		entry.Touch(now)
+		vault.Entries[service] = entry
+		// Counter changes keep the backups: they are not restore points
+		if err := storage.SaveVaultAccess(vault, pwd); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Counter of '%s' resynchronized; the next code uses counter %d.\n", service, next)
+		return nil
+	}
+
+	code, err := otp.HOTP(key)
+	if err != nil {
+		return fmt.Errorf("failed to generate the code for '%s': %w", service, err)
+	}
+	// Saved before the code is shown: a code whose counter was not stored must not be used
+	key.Counter++
+	entry.OTP = &key
+	entry.AccessedAt = now
+	vault.Entries[service] = entry
+	if err := storage.SaveVaultAccess(vault, pwd); err != nil {
+		return fmt.Errorf("failed to save the counter, code not shown: %w", err)
+	}
+
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		fmt.Println(code)
+		return nil
+	}
+	fmt.Printf("🔢 %s  (counter %d)\n", code, key.Counter-1)
+	if !toStdout {
+		if err := copySecret(code, clr); err != nil {
+			fmt.Printf("⚠️  Clipboard copy failed: %v\n", err)
+		} else {
+			fmt.Printf("📋 Code for '%s' copied to clipboard%s.\n", service, clr.note())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go-passman/internal/utils"
+)
+
+// Output formats of list and status (--output).
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+	outputCSV  = "csv"
+	outputTSV  = "tsv"
+)
+
+var outputFormats = []string{outputText, outputJSON, outputYAML, outputCSV, outputTSV}
+
+// addOutputFlag registers -o/--output on cmd.
+func addOutputFlag(cmd *cobra.Command, format *string) {
+	cmd.Flags().StringVarP(format, "output", "o", outputText, "Output format: "+strings.Join(outputFormats, ", "))
+}
+
+// checkOutput returns an error for an unknown output format.
+func checkOutput(format string) error {
+	if !contains(outputFormats, format) {
+		return fmt.Errorf("unknown output format %q (use %s)", format, strings.Join(outputFormats, ", "))
+	}
+	return nil
+}
+
+// writeOutput prints v as JSON or YAML, or header and rows as CSV or TSV.
+func writeOutput(format string, v interface{}, header []string, rows [][]string) error {
+	switch format {
+	case outputJSON:
+		return printJSON(v)
+	case outputYAML:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to encode: %w", err)
+		}
+		if data, err = utils.JSONToYAML(data); err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	case outputCSV, outputTSV:
+		w := csv.NewWriter(os.Stdout)
+		if format == outputTSV {
+			w.Comma = '\t'
+		}
+		w.Write(header)
+		w.WriteAll(rows)
+		if err := w.Error(); err != nil {
+			return fmt.Errorf("failed to write %s: %w", strings.ToUpper(format), err)
+		}
+		return nil
+	}
+	return checkOutput(format)
+}
+
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
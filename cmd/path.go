@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"go-passman/internal/config"
 	"go-passman/internal/storage"
 )
 
@@ -12,6 +13,14 @@ func NewPathCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "path",
 		Short: "Display the path to the vault file",
+		Long: "Display the path to the vault file and where it was configured.\n\n" +
+			"The vault location is taken from, in order of precedence:\n" +
+			"  1. the --vault flag\n" +
+			"  2. the --vault-name flag (a named vault, see 'vault')\n" +
+			"  3. the " + storage.EnvVault + " environment variable\n" +
+			"  4. the named vault selected with 'vault use'\n" +
+			"  5. \"vault = PATH\" in the config file (" + configPathHint() + ")\n" +
+			"  6. vault.json next to the executable",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return handlePath()
 		},
@@ -23,5 +32,14 @@ func NewPathCommand() *cobra.Command {
 func handlePath() error {
 	path := storage.GetVaultPath()
 	fmt.Printf("Vault path: %s\n", path)
+	fmt.Printf("Source: %s\n", storage.GetVaultSource())
 	return nil
 }
+
+// configPathHint returns the config file path for help texts.
+func configPathHint() string {
+	if p, err := config.Path(); err == nil {
+		return p
+	}
+	return "~/.config/go-passman/config"
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go-passman/internal/crypto"
+	"go-passman/internal/storage"
+	"go-passman/internal/utils"
+)
+
+// rekeyOptions holds the rekey flags; zero cost values mean "keep current".
+type rekeyOptions struct {
+	memoryMiB    uint32
+	timeCost     uint32
+	parallelism  uint8
+	keepPassword bool
+}
+
+// NewRekeyCommand creates the rekey command
+func NewRekeyCommand() *cobra.Command {
+	var opts rekeyOptions
+
+	cmd := &cobra.Command{
+		Use:   "rekey",
+		Short: "Change the master password and/or key derivation parameters",
+		Long: "Re-encrypt the vault with a new master password and/or new Argon2id cost parameters.\n" +
+			"The encrypted file is replaced atomically; plaintext is never written to disk.\n" +
+			"Parameters that are not given keep their current values. When the password changes, the backups\n" +
+			"(vault.json.bak.N) are re-encrypted with the new password; backups that do not open with the old\n" +
+			"password are removed, so no copy of the vault opens with a previous password.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.memoryMiB > crypto.MaxMemoryKiB/1024 {
+				return fmt.Errorf("--memory must be at most %d MiB", crypto.MaxMemoryKiB/1024)
+			}
+			return handleRekey(opts)
+		},
+	}
+
+	cmd.Flags().Uint32Var(&opts.memoryMiB, "memory", 0, "Argon2id memory cost in MiB, at most 4096 (default: keep current, 64 for new vaults)")
+	cmd.Flags().Uint32Var(&opts.timeCost, "time", 0, "Argon2id time cost, number of passes, at most 100 (default: keep current)")
+	cmd.Flags().Uint8Var(&opts.parallelism, "parallelism", 0, "Argon2id parallelism, number of lanes, at most 64 (default: keep current)")
+	cmd.Flags().BoolVar(&opts.keepPassword, "keep-password", false, "Keep the current master password (only change KDF parameters)")
+
+	return cmd
+}
+
+func handleRekey(opts rekeyOptions) error {
+	vault, pwd, err := storage.LoadVaultForUpdate()
+	if err != nil {
+		return err
+	}
+	defer storage.Unlock()
+
+	if !vault.Encrypted {
+		fmt.Println("ℹ️  Vault is not encrypted. Run 'go-passman encrypt' to set a master password.")
+		return nil
+	}
+
+	// Start from the loaded vault's parameters (defaults for a legacy vault)
+	params := storage.GetKDFParams()
+	if opts.memoryMiB > 0 {
+		params.Memory = opts.memoryMiB * 1024
+	}
+	if opts.timeCost > 0 {
+		params.Time = opts.timeCost
+	}
+	if opts.parallelism > 0 {
+		params.Parallelism = opts.parallelism
+	}
+	if err := storage.SetKDFParams(params); err != nil {
+		return err
+	}
+
+	newPassword := *pwd
+	if !opts.keepPassword {
+		fmt.Println("Choose the new master password.")
+		newPassword, err = utils.ReadPasswordConfirm()
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := storage.SaveVault(vault, &newPassword); err != nil {
+		return err
+	}
+
+	if opts.keepPassword {
+		fmt.Printf("✅ Vault re-encrypted with %s.\n", params)
+		return nil
+	}
+	fmt.Printf("✅ Master password changed. Vault re-encrypted with %s.\n", params)
+
+	// The backups still open with the old password: move them to the new one
+	removed, err := storage.ReencryptBackups(*pwd, newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to re-encrypt backups (they may still open with the old password): %w", err)
+	}
+	if removed > 0 {
+		fmt.Printf("🗑  Removed %d backup(s) that did not open with the old password.\n", removed)
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"go-passman/internal/models"
 	"go-passman/internal/storage"
 	"go-passman/internal/utils"
 )
@@ -25,6 +26,7 @@ func NewRemoveCommand() *cobra.Command {
 }
 
 func handleRemove() error {
+	// The lock is only taken to remove each entry, not while waiting for input
 	vault, pwd, err := storage.LoadVault()
 	if err != nil {
 		return err
@@ -37,7 +39,7 @@ func handleRemove() error {
 		}
 
 		services := getSortedServices(vault.Entries)
-		service, err := utils.ChooseFromList(services, "Select a service to remove:", "Filter by name (Enter = all): ")
+		service, err := utils.ChooseFromList(services, "Select a service to remove:", searchPrompt, searchFilter(vault.Entries))
 		if err != nil {
 			if errors.Is(err, utils.ErrCancelled) {
 				break
@@ -48,9 +50,19 @@ func handleRemove() error {
 		if !utils.ConfirmAction(fmt.Sprintf("Are you sure you want to remove '%s'?", service)) {
 			fmt.Println("❌ Operation cancelled.")
 		} else {
-			delete(vault.Entries, service)
-
-			if err := storage.SaveVault(vault, pwd); err != nil {
+			before := vault.Entries[service]
+			vault, err = changeVault(pwd, func(vault *models.Vault) error {
+				current, exists := vault.Entries[service]
+				if !exists {
+					return fmt.Errorf("%w: '%s' was removed already", errConflict, service)
+				}
+				if !current.ModifiedAt.Equal(before.ModifiedAt) {
+					return fmt.Errorf("%w: '%s' was changed. Not removed", errConflict, service)
+				}
+				delete(vault.Entries, service)
+				return nil
+			})
+			if err != nil {
 				return err
 			}
 
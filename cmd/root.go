@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"go-passman/internal/storage"
 	"go-passman/internal/web"
@@ -10,26 +12,39 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// skipStorageInit is a command annotation: commands carrying it (and their subcommands) do not resolve the vault path.
+const skipStorageInit = "skip-storage-init"
+
 // NewRootCommand creates the root command
 func NewRootCommand() *cobra.Command {
 	var runWeb bool
+	var lockTimeout time.Duration
+	var vaultFlag, vaultName string
 	rootCmd := &cobra.Command{
 		Use:     "go-passman",
 		Short:   "A simple CLI password manager",
 		Long:    "A simple and secure CLI password manager. Store, manage, encrypt, and decrypt passwords from your terminal.",
 		Version: "0.3.1",
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			// Arguments and flags are valid by now: later errors (e.g. a wrong password) are not usage errors
+			cmd.SilenceUsage = true
+			storage.SetLockTimeout(lockTimeout)
 			if runWeb {
 				return
 			}
-			if err := storage.Init(); err != nil {
+			for c := cmd; c != nil; c = c.Parent() {
+				if c.Annotations[skipStorageInit] != "" {
+					return
+				}
+			}
+			if err := storage.Init(vaultFlag, vaultName); err != nil {
 				fmt.Fprintf(os.Stderr, "Error initializing storage: %v\n", err)
 				os.Exit(1)
 			}
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if runWeb {
-				if err := storage.Init(); err != nil {
+				if err := storage.Init(vaultFlag, vaultName); err != nil {
 					return fmt.Errorf("initializing storage: %w", err)
 				}
 				web.Run()
@@ -38,6 +53,9 @@ func NewRootCommand() *cobra.Command {
 		},
 	}
 
+	rootCmd.PersistentFlags().StringVar(&vaultFlag, "vault", "", "Vault file to use (overrides "+storage.EnvVault+" and the config file)")
+	rootCmd.PersistentFlags().StringVar(&vaultName, "vault-name", "", "Named vault to use (see 'vault'); overrides GO_PASSMAN_VAULT and 'vault use'")
+	rootCmd.PersistentFlags().DurationVar(&lockTimeout, "lock-timeout", storage.DefaultLockTimeout, "How long to wait when another go-passman process is modifying the vault (0 = fail immediately)")
 	rootCmd.Flags().BoolVarP(&runWeb, "web", "w", false, "Run as web server (simple UI at http://127.0.0.1:8080)")
 
 	// Add subcommands
@@ -45,6 +63,7 @@ func NewRootCommand() *cobra.Command {
 		NewAddCommand(),
 		NewRemoveCommand(),
 		NewCopyCommand(),
+		NewGetCommand(),
 		NewListCommand(),
 		NewUpdateCommand(),
 		NewOpenCommand(),
@@ -52,7 +71,39 @@ func NewRootCommand() *cobra.Command {
 		NewEncryptCommand(),
 		NewDecryptCommand(),
 		NewStatusCommand(),
+		NewRekeyCommand(),
+		NewBackupCommand(),
+		NewVaultCommand(),
+		NewGenerateCommand(),
+		NewAuditCommand(),
+		NewHistoryCommand(),
+		NewDueCommand(),
+		NewTagCommand(),
+		NewTOTPCommand(),
+		NewHOTPCommand(),
+		NewClearClipboardCommand(),
 	)
 
 	return rootCmd
 }
+
+// ExitError ends a command with Code as the process exit status, for results that are not failures
+// (e.g. 'due' reporting passwords to rotate) or scripted failures already reported. Commands return it
+// through exitStatus, which silences cobra's error and usage output; main exits with Code.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// exitStatus silences cobra's error and usage output when err is an ExitError, whose message (if any) the
+// command has printed already.
+func exitStatus(cmd *cobra.Command, err error) error {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		cmd.SilenceErrors, cmd.SilenceUsage = true, true
+	}
+	return err
+}
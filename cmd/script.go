@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go-passman/internal/audit"
+	"go-passman/internal/models"
+	"go-passman/internal/storage"
+	"go-passman/internal/utils"
+	"golang.org/x/term"
+)
+
+// Exit codes of the non-interactive add/update forms (1 is any other error, e.g. an unknown flag).
+const (
+	exitUsage    = 2 // invalid or missing arguments
+	exitNotFound = 3 // update: no such entry
+	exitExists   = 4 // add: the entry already exists
+	exitWeak     = 5 // the password is weak and --allow-weak was not given
+	exitVault    = 6 // the vault cannot be opened: encrypted without GO_PASSMAN_PASSWORD, wrong password, busy
+	exitSave     = 7 // the vault cannot be saved
+)
+
+// settableFields are the entry fields update --set can change.
+var settableFields = []string{"login", "host", "comment", "folder", "tags"}
+
+// scriptOptions holds the flags of the non-interactive add/update forms.
+type scriptOptions struct {
+	login, host, comment string   // add
+	set                  []string // update: FIELD=VALUE
+	passwordStdin        bool
+	allowWeak            bool
+}
+
+// addScriptFlags registers the non-interactive flags on cmd: --login/--host/--comment for add, --set for update.
+func addScriptFlags(cmd *cobra.Command, opts *scriptOptions, forUpdate bool) {
+	if forUpdate {
+		cmd.Flags().StringArrayVar(&opts.set, "set", nil, "Set FIELD=VALUE ("+strings.Join(settableFields, ", ")+"; repeatable, empty value clears)")
+	} else {
+		cmd.Flags().StringVar(&opts.login, "login", "", "Login of the new entry")
+		cmd.Flags().StringVar(&opts.host, "host", "", "Host of the new entry")
+		cmd.Flags().StringVar(&opts.comment, "comment", "", "Comment of the new entry")
+	}
+	cmd.Flags().BoolVar(&opts.passwordStdin, "password-stdin", false, "Read the password from the first line of stdin")
+	cmd.Flags().BoolVar(&opts.allowWeak, "allow-weak", false, "Save a weak password without failing (non-interactive form)")
+}
+
+// given reports whether a flag of the non-interactive forms was used.
+func (o *scriptOptions) given(cmd *cobra.Command) bool {
+	for _, name := range []string{"login", "host", "comment", "set", "password-stdin", "allow-weak"} {
+		if f := cmd.Flags().Lookup(name); f != nil && f.Changed {
+			return true
+		}
+	}
+	return false
+}
+
+// entryFlagsFromCmd reads and checks the entry flags shared by add and update, before anything is asked.
+func entryFlagsFromCmd(cmd *cobra.Command, gen *generatorOptions, rot *rotationOptions, fields *fieldOptions, org *organizeOptions, otpOpts *otpOptions) error {
+	if err := rot.fromFlags(cmd); err != nil {
+		return err
+	}
+	if err := fields.validate(); err != nil {
+		return err
+	}
+	org.fromFlags(cmd)
+	if err := otpOpts.validate(); err != nil {
+		return err
+	}
+	gen.fromFlags(cmd)
+	return nil
+}
+
+// scriptFail reports a failure of a non-interactive command on stderr and returns an ExitError with code,
+// which the command returns through exitStatus.
+func scriptFail(code int, format string, args ...interface{}) error {
+	fmt.Fprintf(os.Stderr, "❌ "+format+"\n", args...)
+	return &ExitError{Code: code}
+}
+
+// checkNoPrompt fails when a flag would make add/update ask for a value.
+func checkNoPrompt(fields *fieldOptions, otpOpts *otpOptions) error {
+	if fields.needsInput() {
+		return scriptFail(exitUsage, "--secret needs NAME=VALUE when the service is given as an argument")
+	}
+	if otpOpts.value == "-" {
+		return scriptFail(exitUsage, "--otp - asks for the secret; pass the URI or secret when the service is given as an argument")
+	}
+	return nil
+}
+
+// readPasswordStdin reads the password from the first line of stdin, without the line ending.
+func readPasswordStdin() (string, error) {
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", scriptFail(exitUsage, "failed to read the password from stdin: %v", err)
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", scriptFail(exitUsage, "no password on stdin")
+	}
+	return password, nil
+}
+
+// scriptPassword returns the password of a non-interactive add/update: from stdin or generated from the
+// generator flags without asking. ok is false when neither was requested.
+func scriptPassword(opts *scriptOptions, gen *generatorOptions, generate bool) (password string, entropy float64, ok bool, err error) {
+	switch {
+	case opts.passwordStdin && generate:
+		return "", 0, false, scriptFail(exitUsage, "use either --password-stdin or --generate")
+	case opts.passwordStdin:
+		password, err := readPasswordStdin()
+		return password, 0, err == nil, err
+	case generate:
+		gen.interactive = false
+		if err := gen.validate(); err != nil {
+			return "", 0, false, scriptFail(exitUsage, "%v", err)
+		}
+		password, entropy, err := gen.generate()
+		if err != nil {
+			return "", 0, false, scriptFail(exitUsage, "%v", err)
+		}
+		return password, entropy, true, nil
+	}
+	return "", 0, false, nil
+}
+
+// checkStrength fails on a weak password unless --allow-weak was given.
+func checkStrength(opts *scriptOptions, password, service, login string) error {
+	s := utils.EstimateStrength(password, service, login)
+	if s.Score >= audit.DefaultMinScore {
+		return nil
+	}
+	if opts.allowWeak {
+		fmt.Fprintf(os.Stderr, "⚠️  Saving a %s password (~%.0f bits).\n", s.Label(), s.Bits)
+		return nil
+	}
+	return scriptFail(exitWeak, "the password is %s (~%.0f bits): %s. Use --allow-weak to save it anyway.", s.Label(), s.Bits, strings.Join(s.Warnings, "; "))
+}
+
+// loadVaultNoPrompt opens the vault for a non-interactive change; on success the caller must Unlock.
+func loadVaultNoPrompt() (*models.Vault, *string, error) {
+	vault, pwd, err := storage.LoadVaultForUpdateNoPrompt()
+	if errors.Is(err, storage.ErrPasswordRequired) {
+		return nil, nil, scriptFail(exitVault, "the vault is encrypted: set %s to its password", storage.EnvPassword)
+	}
+	if err != nil {
+		return nil, nil, scriptFail(exitVault, "%v", err)
+	}
+	return vault, pwd, nil
+}
+
+// reportGenerated copies a generated password in a terminal, or prints it to stdout when piped.
+func reportGenerated(message, password string, entropy float64, gen *generatorOptions) {
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		fmt.Fprintln(os.Stderr, message)
+		fmt.Println(password)
+		return
+	}
+	if err := copySecret(password, &gen.clear); err != nil {
+		fmt.Printf("%s\n⚠️  Clipboard copy failed: %v\n", message, err)
+		return
+	}
+	fmt.Printf("%s 📋 Copied to clipboard%s.\n", message, gen.clear.note())
+	printEntropy(entropy)
+}
+
+// handleAddScripted adds service from flags and stdin without any prompt.
+func handleAddScripted(service string, opts *scriptOptions, gen *generatorOptions, generate bool, rot *rotationOptions, fields *fieldOptions, org *organizeOptions, otpOpts *otpOptions) error {
+	service = strings.TrimSpace(service)
+	if service == "" {
+		return scriptFail(exitUsage, "the service name is empty")
+	}
+	if err := checkNoPrompt(fields, otpOpts); err != nil {
+		return err
+	}
+	password, entropy, ok, err := scriptPassword(opts, gen, generate)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return scriptFail(exitUsage, "a password is required: use --password-stdin or --generate")
+	}
+
+	vault, pwd, err := loadVaultNoPrompt()
+	if err != nil {
+		return err
+	}
+	defer storage.Unlock()
+	if _, exists := vault.Entries[service]; exists {
+		return scriptFail(exitExists, "service '%s' already exists. Use 'update %s' to change it.", service, service)
+	}
+	if err := checkStrength(opts, password, service, opts.login); err != nil {
+		return err
+	}
+
+	entry := models.PasswordEntry{
+		Login:   opts.login,
+		Host:    opts.host,
+		Comment: opts.comment,
+	}
+	if err := fields.apply(&entry); err != nil {
+		return scriptFail(exitUsage, "%v", err)
+	}
+	org.apply(&entry)
+	if err := otpOpts.apply(&entry); err != nil {
+		return scriptFail(exitUsage, "%v", err)
+	}
+	now := time.Now()
+	entry.SetPassword(password, now)
+	entry.Touch(now)
+	rot.apply(&entry)
+	vault.Entries[service] = entry
+
+	if err := storage.SaveVault(vault, pwd); err != nil {
+		return scriptFail(exitSave, "%v", err)
+	}
+	message := fmt.Sprintf("✅ Password for '%s' saved.", service)
+	if generate {
+		reportGenerated(message, password, entropy, gen)
+	} else {
+		fmt.Println(message)
+	}
+	return nil
+}
+
+// handleUpdateScripted changes the entry serviceOrNum from flags and stdin without any prompt.
+func handleUpdateScripted(serviceOrNum string, opts *scriptOptions, gen *generatorOptions, generate bool, rot *rotationOptions, fields *fieldOptions, org *organizeOptions, otpOpts *otpOptions) error {
+	sets := make(map[string]string, len(opts.set))
+	for _, s := range opts.set {
+		name, value, ok := strings.Cut(s, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || !contains(settableFields, name) {
+			return scriptFail(exitUsage, "invalid --set %q (use FIELD=VALUE with FIELD one of %s; --field for custom fields)", s, strings.Join(settableFields, ", "))
+		}
+		sets[name] = value
+	}
+	if err := checkNoPrompt(fields, otpOpts); err != nil {
+		return err
+	}
+	password, entropy, changePassword, err := scriptPassword(opts, gen, generate)
+	if err != nil {
+		return err
+	}
+	if len(sets) == 0 && !changePassword && !fields.given() && !org.given() && !otpOpts.given() && !rot.given() {
+		return scriptFail(exitUsage, "nothing to update: use --set, --password-stdin, --generate or another field flag")
+	}
+
+	vault, pwd, err := loadVaultNoPrompt()
+	if err != nil {
+		return err
+	}
+	defer storage.Unlock()
+	service, err := resolveServiceOrNumber(vault.Entries, serviceOrNum)
+	if err != nil {
+		return scriptFail(exitNotFound, "%v", err)
+	}
+	entry := vault.Entries[service]
+
+	for name, value := range sets {
+		switch name {
+		case "login":
+			entry.Login = value
+		case "host":
+			entry.Host = value
+		case "comment":
+			entry.Comment = value
+		case "folder":
+			entry.Folder = models.CleanFolder(value)
+		case "tags":
+			entry.Tags = nil
+			entry.AddTags(splitList([]string{value})...)
+		}
+	}
+	if err := fields.apply(&entry); err != nil {
+		return scriptFail(exitUsage, "%v", err)
+	}
+	org.apply(&entry)
+	if err := otpOpts.apply(&entry); err != nil {
+		return scriptFail(exitUsage, "%v", err)
+	}
+	now := time.Now()
+	if changePassword {
+		if err := checkStrength(opts, password, service, entry.Login); err != nil {
+			return err
+		}
+		entry.SetPassword(password, now)
+	}
+	rot.apply(&entry)
+	entry.Touch(now)
+	vault.Entries[service] = entry
+
+	if err := storage.SaveVault(vault, pwd); err != nil {
+		return scriptFail(exitSave, "%v", err)
+	}
+	message := fmt.Sprintf("✅ '%s' updated.", service)
+	if generate {
+		reportGenerated(message, password, entropy, gen)
+	} else {
+		fmt.Println(message)
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
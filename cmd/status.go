@@ -2,34 +2,100 @@ package cmd
 
 import (
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
+	"go-passman/internal/audit"
 	"go-passman/internal/storage"
 )
 
+// statusRecord is the vault status printed by status --output.
+type statusRecord struct {
+	Path          string     `json:"path"`
+	PathSource    string     `json:"path_source"`
+	Encrypted     bool       `json:"encrypted"`
+	KDF           *kdfRecord `json:"kdf"` // null when not encrypted
+	Entries       int        `json:"entries"`
+	Expired       int        `json:"expired"`
+	DueSoon       int        `json:"due_soon"`
+	DueWithinDays int        `json:"due_within_days"`
+}
+
+// kdfRecord holds the key derivation parameters of an encrypted vault. For the legacy PBKDF2 format
+// time is the iteration count and memory_kib and parallelism are 0.
+type kdfRecord struct {
+	Algorithm   string `json:"algorithm"`
+	MemoryKiB   uint32 `json:"memory_kib"`
+	Time        uint32 `json:"time"`
+	Parallelism uint8  `json:"parallelism"`
+}
+
+// statusColumns is the header of status --output csv/tsv (the kdf_ columns are empty when not encrypted).
+var statusColumns = []string{"path", "path_source", "encrypted", "kdf_algorithm", "kdf_memory_kib", "kdf_time",
+	"kdf_parallelism", "entries", "expired", "due_soon", "due_within_days"}
+
 // NewStatusCommand creates the status command
 func NewStatusCommand() *cobra.Command {
+	var output string
+
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Display the status of the vault",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return handleStatus()
+			if err := checkOutput(output); err != nil {
+				return err
+			}
+			return handleStatus(output)
 		},
 	}
 
+	addOutputFlag(cmd, &output)
+
 	return cmd
 }
 
-func handleStatus() error {
+func handleStatus(output string) error {
 	vault, _, err := storage.LoadVault()
 	if err != nil {
 		return err
 	}
 
+	if output != outputText {
+		rec := statusRecord{
+			Path:          storage.GetVaultPath(),
+			PathSource:    storage.GetVaultSource(),
+			Encrypted:     vault.Encrypted,
+			Entries:       len(vault.Entries),
+			DueWithinDays: int(audit.DefaultDueWithin.Hours() / 24),
+		}
+		if vault.Encrypted {
+			if params, err := storage.VaultKDF(); err == nil {
+				rec.KDF = &kdfRecord{Algorithm: params.Algorithm, MemoryKiB: params.Memory, Time: params.Time, Parallelism: params.Parallelism}
+			}
+		}
+		rec.Expired, rec.DueSoon = audit.CountDue(audit.Due(vault.Entries, time.Now(), audit.DefaultDueWithin))
+		kdf := make([]string, 4)
+		if rec.KDF != nil {
+			kdf = []string{rec.KDF.Algorithm, strconv.FormatUint(uint64(rec.KDF.MemoryKiB), 10),
+				strconv.FormatUint(uint64(rec.KDF.Time), 10), strconv.FormatUint(uint64(rec.KDF.Parallelism), 10)}
+		}
+		row := append([]string{rec.Path, rec.PathSource, strconv.FormatBool(rec.Encrypted)}, kdf...)
+		row = append(row, strconv.Itoa(rec.Entries), strconv.Itoa(rec.Expired), strconv.Itoa(rec.DueSoon), strconv.Itoa(rec.DueWithinDays))
+		return writeOutput(output, rec, statusColumns, [][]string{row})
+	}
+
 	fmt.Println("🔐 Vault Status:")
 	fmt.Printf("  Entries: %d\n", len(vault.Entries))
 	fmt.Printf("  Encrypted: %v\n", vault.Encrypted)
+	if vault.Encrypted {
+		if params, err := storage.VaultKDF(); err == nil {
+			fmt.Printf("  KDF: %s\n", params)
+		}
+	}
 	fmt.Printf("  Path: %s\n", storage.GetVaultPath())
+	fmt.Printf("  Path source: %s\n", storage.GetVaultSource())
+	printDueWarning(vault.Entries)
 
 	return nil
 }
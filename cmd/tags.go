@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go-passman/internal/models"
+	"go-passman/internal/search"
+	"go-passman/internal/storage"
+	"go-passman/internal/utils"
+)
+
+// organizeOptions holds the tag and folder flags of add and update.
+type organizeOptions struct {
+	tags   []string
+	untags []string
+	folder string
+
+	setFolder bool
+}
+
+// addOrganizeFlags registers the tag and folder flags on cmd; --untag only where tags can exist already.
+func addOrganizeFlags(cmd *cobra.Command, opts *organizeOptions, withUntag bool) {
+	cmd.Flags().StringArrayVar(&opts.tags, "tag", nil, "Add a tag (repeatable or comma-separated)")
+	if withUntag {
+		cmd.Flags().StringArrayVar(&opts.untags, "untag", nil, "Remove a tag (repeatable or comma-separated)")
+	}
+	cmd.Flags().StringVar(&opts.folder, "folder", "", "Put the entry in a folder, e.g. work/servers (\"\" = none)")
+}
+
+// fromFlags records which organize flags were given on cmd.
+func (o *organizeOptions) fromFlags(cmd *cobra.Command) {
+	o.setFolder = cmd.Flags().Changed("folder")
+}
+
+// given reports whether any tag or folder flag was used; then the prompts are skipped.
+func (o *organizeOptions) given() bool {
+	return len(o.tags)+len(o.untags) > 0 || o.setFolder
+}
+
+// apply changes the entry's tags and folder as given by the flags.
+func (o *organizeOptions) apply(entry *models.PasswordEntry) {
+	entry.RemoveTags(splitList(o.untags)...)
+	entry.AddTags(splitList(o.tags)...)
+	if o.setFolder {
+		entry.Folder = models.CleanFolder(o.folder)
+	}
+}
+
+// edit applies the flags, or asks for tags and folder when no flag was given.
+func (o *organizeOptions) edit(entry *models.PasswordEntry) error {
+	if o.given() {
+		o.apply(entry)
+		return nil
+	}
+
+	tags, err := utils.ReadInput(fmt.Sprintf("Tags, comma-separated [%s] (Enter = keep, - = none): ", strings.Join(entry.Tags, ", ")))
+	if err != nil {
+		return err
+	}
+	switch tags {
+	case "":
+	case "-":
+		entry.Tags = nil
+	default:
+		entry.Tags = nil
+		entry.AddTags(splitList([]string{tags})...)
+	}
+
+	folder, err := utils.ReadInput(fmt.Sprintf("Folder, e.g. work/servers [%s] (Enter = keep, - = none): ", entry.Folder))
+	if err != nil {
+		return err
+	}
+	switch folder {
+	case "":
+	case "-":
+		entry.Folder = ""
+	default:
+		entry.Folder = models.CleanFolder(folder)
+	}
+	return nil
+}
+
+// splitList splits comma-separated flag values into single items (tags, codes).
+func splitList(values []string) []string {
+	var tags []string
+	for _, v := range values {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				tags = append(tags, t)
+			}
+		}
+	}
+	return tags
+}
+
+// matchesOrganize reports whether entry has all tags and is in folder (or a subfolder).
+func matchesOrganize(entry models.PasswordEntry, tags []string, folder string) bool {
+	for _, t := range tags {
+		if !entry.HasTag(t) {
+			return false
+		}
+	}
+	return entry.InFolder(folder)
+}
+
+// NewTagCommand creates the tag command
+func NewTagCommand() *cobra.Command {
+	var add, remove, whereTags []string
+	var whereFolder, filter string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "tag [service|N...]",
+		Short: "Add or remove tags on many entries at once, or show tag counts",
+		Long: "Select entries by name or number, and/or with --where-tag, --where-folder and --filter (all must match),\n" +
+			"then change their tags with --add and --remove. Without --add/--remove the tags and folders of the\n" +
+			"selected entries (all entries by default) are counted.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleTag(args, splitList(add), splitList(remove), splitList(whereTags), whereFolder, filter, yes)
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&add, "add", "a", nil, "Tag to add (repeatable or comma-separated)")
+	cmd.Flags().StringArrayVarP(&remove, "remove", "r", nil, "Tag to remove (repeatable or comma-separated)")
+	cmd.Flags().StringArrayVar(&whereTags, "where-tag", nil, "Only entries with this tag (repeatable)")
+	cmd.Flags().StringVar(&whereFolder, "where-folder", "", "Only entries in this folder or its subfolders")
+	cmd.Flags().StringVarP(&filter, "filter", "f", "", "Only entries matching this search (like list --filter)")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation")
+
+	return cmd
+}
+
+// errNothingChanged ends a tag change without saving when the entries already have the requested tags.
+var errNothingChanged = errors.New("nothing to change")
+
+func handleTag(args, add, remove, whereTags []string, whereFolder, filter string, yes bool) error {
+	editing := len(add)+len(remove) > 0
+	// The lock is only taken to save, not while waiting for the confirmation
+	vault, pwd, err := storage.LoadVault()
+	if err != nil {
+		return err
+	}
+
+	candidates := getSortedServices(vault.Entries)
+	if len(args) > 0 {
+		candidates = candidates[:0:0]
+		for _, arg := range args {
+			service, err := resolveServiceOrNumber(vault.Entries, arg)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				os.Exit(1)
+			}
+			candidates = append(candidates, service)
+		}
+	}
+	var selected []string
+	query := search.Parse(filter)
+	for _, service := range candidates {
+		if matchesOrganize(vault.Entries[service], whereTags, whereFolder) && query.Matches(service, vault.Entries[service]) {
+			selected = append(selected, service)
+		}
+	}
+	if len(selected) == 0 {
+		fmt.Println("📭 No entries match.")
+		return nil
+	}
+
+	if !editing {
+		selectedVault := models.NewVault()
+		for _, s := range selected {
+			selectedVault.Entries[s] = vault.Entries[s]
+		}
+		printCounts("🏷️  Tags", selectedVault.TagCounts())
+		printCounts("📁 Folders", selectedVault.FolderCounts())
+		return nil
+	}
+
+	fmt.Printf("Entries (%d): %s\n", len(selected), strings.Join(selected, ", "))
+	if !yes && !utils.ConfirmAction(fmt.Sprintf("Change tags of %d entries?", len(selected))) {
+		fmt.Println("❌ Operation cancelled.")
+		return nil
+	}
+
+	// Tags are added to and removed from the entries as saved now, keeping other changes made meanwhile
+	changed := 0
+	_, err = changeVault(pwd, func(vault *models.Vault) error {
+		now := time.Now()
+		for _, service := range selected {
+			entry, exists := vault.Entries[service]
+			if !exists {
+				return fmt.Errorf("%w: '%s' was removed. Tags not changed", errConflict, service)
+			}
+			removed := entry.RemoveTags(remove...)
+			added := entry.AddTags(add...)
+			if added || removed {
+				entry.Touch(now)
+				vault.Entries[service] = entry
+				changed++
+			}
+		}
+		if changed == 0 {
+			return errNothingChanged
+		}
+		return nil
+	})
+	if errors.Is(err, errNothingChanged) {
+		fmt.Println("✅ Nothing to change.")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Tags updated on %d entries.\n", changed)
+	return nil
+}
+
+// printCounts prints names with their entry counts, sorted by name.
+func printCounts(title string, counts map[string]int) {
+	if len(counts) == 0 {
+		fmt.Printf("%s: none\n", title)
+		return
+	}
+	names := make([]string, 0, len(counts))
+	for n := range counts {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	fmt.Printf("%s:\n", title)
+	for _, n := range names {
+		fmt.Printf("  %s (%d)\n", n, counts[n])
+	}
+}
@@ -3,9 +3,12 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"go-passman/internal/models"
+	"go-passman/internal/otp"
 	"go-passman/internal/storage"
 	"go-passman/internal/utils"
 
@@ -15,24 +18,61 @@ import (
 // NewUpdateCommand creates the update command
 func NewUpdateCommand() *cobra.Command {
 	var generate bool
+	var gen generatorOptions
+	var rot rotationOptions
+	var fields fieldOptions
+	var org organizeOptions
+	var otpOpts otpOptions
+	var script scriptOptions
 
 	cmd := &cobra.Command{
-		Use:   "update",
+		Use:   "update [service|N]",
 		Short: "Update an existing service or entry in the vault",
+		Long: "Without arguments update lets you pick entries and asks for every value; field, tag, folder, one-time\n" +
+			"password and rotation flags replace those questions for the first entry picked only. With a service\n" +
+			"name or number it never prompts: only what --set FIELD=VALUE and the other flags say is changed, and\n" +
+			"a new password comes from --password-stdin or --generate.\n" +
+			"Exit codes: 2 invalid arguments, 3 service not found, 5 weak password (see --allow-weak),\n" +
+			"6 vault cannot be opened (set GO_PASSMAN_PASSWORD for an encrypted vault), 7 save failed.",
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if generate {
-				return handleUpdateGenerate()
+			err := entryFlagsFromCmd(cmd, &gen, &rot, &fields, &org, &otpOpts)
+			if len(args) == 1 {
+				if err != nil {
+					return exitStatus(cmd, scriptFail(exitUsage, "%v", err))
+				}
+				return exitStatus(cmd, handleUpdateScripted(args[0], &script, &gen, generate || !gen.interactive, &rot, &fields, &org, &otpOpts))
 			}
-			return handleUpdateManual()
+			if script.given(cmd) {
+				return fmt.Errorf("--set, --password-stdin and --allow-weak need the service name or number as an argument")
+			}
+			if err != nil {
+				return err
+			}
+			// Any generator flag implies -g
+			if generate || !gen.interactive {
+				if err := gen.validate(); err != nil {
+					return err
+				}
+				return handleUpdateGenerate(&gen, &rot, &fields, &org, &otpOpts)
+			}
+			return handleUpdateManual(&rot, &fields, &org, &otpOpts)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&generate, "generate", "g", false, "Generate a new random password")
+	addGeneratorFlags(cmd, &gen)
+	addRotationFlags(cmd, &rot)
+	addOrganizeFlags(cmd, &org, true)
+	addFieldFlags(cmd, &fields, true)
+	addOTPFlags(cmd, &otpOpts, true)
+	addScriptFlags(cmd, &script, true)
 
 	return cmd
 }
 
-func handleUpdateManual() error {
+func handleUpdateManual(rot *rotationOptions, fields *fieldOptions, org *organizeOptions, otpOpts *otpOptions) error {
+	// The lock is only taken to save each entry, not while waiting for input
 	vault, pwd, err := storage.LoadVault()
 	if err != nil {
 		return err
@@ -45,7 +85,7 @@ func handleUpdateManual() error {
 		}
 
 		services := getSortedServices(vault.Entries)
-		service, err := utils.ChooseFromList(services, "Select a service to update:", "Filter by name (Enter = all): ")
+		service, err := utils.ChooseFromList(services, "Select a service to update:", searchPrompt, searchFilter(vault.Entries))
 		if err != nil {
 			if errors.Is(err, utils.ErrCancelled) {
 				break
@@ -53,7 +93,8 @@ func handleUpdateManual() error {
 			return err
 		}
 
-		entry := vault.Entries[service]
+		before := vault.Entries[service]
+		entry := before
 
 		// Login: show current; Enter = keep, type = replace
 		login, err := utils.ReadInput(fmt.Sprintf("Login [%s]: ", entry.Login))
@@ -82,24 +123,42 @@ func handleUpdateManual() error {
 			entry.Comment = comment
 		}
 
+		if err := fields.edit(&entry); err != nil {
+			return err
+		}
+		if err := org.edit(&entry); err != nil {
+			return err
+		}
+		if err := otpOpts.apply(&entry); err != nil {
+			return err
+		}
+
 		// Password: Enter = keep current, type new = replace (current not shown)
 		password, err := utils.ReadPassword("Password (Enter to keep current): ")
 		if err != nil {
 			return err
 		}
 		if password != "" {
-			entry.Password = password
+			if confirmStrength(password, service, entry.Login) {
+				entry.SetPassword(password, time.Now())
+			} else {
+				fmt.Println("↩️  Keeping the current password.")
+			}
 		}
 
-		vault.Entries[service] = entry
-
-		if err := storage.SaveVault(vault, pwd); err != nil {
+		rot.apply(&entry)
+		entry.Touch(time.Now())
+		vault, err = changeVault(pwd, func(vault *models.Vault) error {
+			return replaceEntry(vault, service, before, entry)
+		})
+		if err != nil {
 			return err
 		}
 
 		fmt.Printf("✅ Password for '%s' updated.\n", service)
 		printEntrySummary(service, &entry)
 
+		applyEntryFlagsOnce(rot, fields, org, otpOpts)
 		printListCompact(getSortedServices(vault.Entries), vault.Entries)
 		if !utils.ConfirmActionWithTimeout("Continue?", 30*time.Second) {
 			break
@@ -108,7 +167,8 @@ func handleUpdateManual() error {
 	return nil
 }
 
-func handleUpdateGenerate() error {
+func handleUpdateGenerate(gen *generatorOptions, rot *rotationOptions, fields *fieldOptions, org *organizeOptions, otpOpts *otpOptions) error {
+	// The lock is only taken to save each entry, not while waiting for input
 	vault, pwd, err := storage.LoadVault()
 	if err != nil {
 		return err
@@ -121,7 +181,7 @@ func handleUpdateGenerate() error {
 		}
 
 		services := getSortedServices(vault.Entries)
-		service, err := utils.ChooseFromList(services, "Select a service to update:", "Filter by name (Enter = all): ")
+		service, err := utils.ChooseFromList(services, "Select a service to update:", searchPrompt, searchFilter(vault.Entries))
 		if err != nil {
 			if errors.Is(err, utils.ErrCancelled) {
 				break
@@ -129,7 +189,8 @@ func handleUpdateGenerate() error {
 			return err
 		}
 
-		entry := vault.Entries[service]
+		before := vault.Entries[service]
+		entry := before
 
 		// Login: show current; Enter = keep, type = replace
 		login, err := utils.ReadInput(fmt.Sprintf("Login [%s]: ", entry.Login))
@@ -158,25 +219,46 @@ func handleUpdateGenerate() error {
 			entry.Comment = comment
 		}
 
-		// Generate new password (replaces current)
-		length, useNumbers, useSpecial := utils.ChoosePasswordOptions()
-		password := utils.GeneratePassword(length, useNumbers, useSpecial)
-		entry.Password = password
+		if err := fields.edit(&entry); err != nil {
+			return err
+		}
+		if err := org.edit(&entry); err != nil {
+			return err
+		}
+		if err := otpOpts.apply(&entry); err != nil {
+			return err
+		}
 
-		vault.Entries[service] = entry
+		// Generate new password (replaces current)
+		password, entropy, err := gen.generate()
+		if err != nil {
+			return err
+		}
+		if !confirmStrength(password, service, entry.Login) {
+			fmt.Println("❌ Not saved.")
+			break
+		}
+		entry.SetPassword(password, time.Now())
 
-		if err := storage.SaveVault(vault, pwd); err != nil {
+		rot.apply(&entry)
+		entry.Touch(time.Now())
+		vault, err = changeVault(pwd, func(vault *models.Vault) error {
+			return replaceEntry(vault, service, before, entry)
+		})
+		if err != nil {
 			return err
 		}
 
 		// Copy to clipboard
-		if err := utils.CopyToClipboard(password); err != nil {
+		if err := copySecret(password, &gen.clear); err != nil {
 			fmt.Printf("⚠️ Password updated but clipboard copy failed: %v\n", err)
 		} else {
-			fmt.Printf("✅ Password for '%s' updated and copied to clipboard.\n", service)
+			fmt.Printf("✅ Password for '%s' updated and copied to clipboard%s.\n", service, gen.clear.note())
 		}
+		printEntropy(entropy)
 		printEntrySummary(service, &entry)
 
+		applyEntryFlagsOnce(rot, fields, org, otpOpts)
 		printListCompact(getSortedServices(vault.Entries), vault.Entries)
 		if !utils.ConfirmActionWithTimeout("Continue?", 30*time.Second) {
 			break
@@ -185,6 +267,64 @@ func handleUpdateGenerate() error {
 	return nil
 }
 
+// applyEntryFlagsOnce clears the entry flags (--field, --tag, --otp, --rotate, ...) once the interactive
+// loop has applied them to the first entry picked, so the next entries are asked for every value instead.
+func applyEntryFlagsOnce(rot *rotationOptions, fields *fieldOptions, org *organizeOptions, otpOpts *otpOptions) {
+	if !rot.given() && !fields.given() && !org.given() && !otpOpts.given() {
+		return
+	}
+	fmt.Println("ℹ️  The field, tag, folder, one-time password and rotation flags were applied to this entry only.")
+	*rot, *fields, *org, *otpOpts = rotationOptions{}, fieldOptions{}, organizeOptions{}, otpOptions{}
+}
+
+// errConflict is wrapped by changes that another process's save made impossible.
+var errConflict = errors.New("another process changed the vault meanwhile")
+
+// changeVault reads the vault again under the lock, applies change and saves it, so commands can ask for
+// input without holding the lock and keep what other processes saved in the meantime. It returns the saved
+// vault; when change fails with errConflict it exits like the other ❌ checks.
+func changeVault(pwd *string, change func(vault *models.Vault) error) (*models.Vault, error) {
+	var saved *models.Vault
+	err := storage.WithLock(func() error {
+		vault, _, err := storage.LoadVaultWithPassword(pwd)
+		if err != nil {
+			return err
+		}
+		if err := change(vault); err != nil {
+			return err
+		}
+		if err := storage.SaveVault(vault, pwd); err != nil {
+			return err
+		}
+		saved = vault
+		return nil
+	})
+	if errors.Is(err, errConflict) {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	return saved, err
+}
+
+// replaceEntry stores entry, edited from before, in vault. It fails when another process removed or changed
+// the entry meanwhile. The last-used time and, unless the edit replaced the secret, the HOTP counter come from
+// vault: they are saved without counting as a change.
+func replaceEntry(vault *models.Vault, service string, before, entry models.PasswordEntry) error {
+	current, exists := vault.Entries[service]
+	if !exists {
+		return fmt.Errorf("%w: '%s' was removed. Not saved", errConflict, service)
+	}
+	if !current.ModifiedAt.Equal(before.ModifiedAt) {
+		return fmt.Errorf("%w: '%s' was changed. Not saved", errConflict, service)
+	}
+	entry.AccessedAt = current.AccessedAt
+	if entry.OTP == before.OTP {
+		entry.OTP = current.OTP
+	}
+	vault.Entries[service] = entry
+	return nil
+}
+
 // printEntrySummary prints the entry fields after update (password masked).
 func printEntrySummary(service string, entry *models.PasswordEntry) {
 	fmt.Println()
@@ -193,7 +333,15 @@ func printEntrySummary(service string, entry *models.PasswordEntry) {
 	fmt.Printf("    Login:   %s\n", orEmpty(entry.Login))
 	fmt.Printf("    Host:    %s\n", orEmpty(entry.Host))
 	fmt.Printf("    Comment: %s\n", orEmpty(entry.Comment))
+	fmt.Printf("    Tags:    %s\n", orEmpty(strings.Join(entry.Tags, ", ")))
+	fmt.Printf("    Folder:  %s\n", orEmpty(entry.Folder))
 	fmt.Println("    Password: ****")
+	for _, f := range entry.Fields {
+		fmt.Printf("    %s: %s\n", f.Name, fieldValue(f))
+	}
+	if entry.OTP != nil {
+		fmt.Printf("    One-time password: %s\n", otp.Label(*entry.OTP))
+	}
 	fmt.Println()
 }
 
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"go-passman/internal/config"
+	"go-passman/internal/models"
+	"go-passman/internal/storage"
+	"go-passman/internal/utils"
+)
+
+// NewVaultCommand creates the vault command group (named vaults)
+func NewVaultCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vault",
+		Short: "Manage named vaults (create, list, use, delete)",
+		Long: "Keep several vaults (e.g. personal, staging, production) as named vaults in the config directory.\n" +
+			"'vault use NAME' selects the vault used by default; --vault-name NAME selects one for a single command.",
+		// Vault management works without resolving the active vault (it may be the one being deleted)
+		Annotations: map[string]string{skipStorageInit: "true"},
+	}
+
+	cmd.AddCommand(
+		newVaultCreateCommand(),
+		newVaultListCommand(),
+		newVaultUseCommand(),
+		newVaultDeleteCommand(),
+	)
+
+	return cmd
+}
+
+func newVaultCreateCommand() *cobra.Command {
+	var encrypt, use bool
+
+	cmd := &cobra.Command{
+		Use:   "create NAME",
+		Short: "Create a new empty named vault",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleVaultCreate(args[0], encrypt, use)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&encrypt, "encrypt", "e", false, "Encrypt the new vault with a master password")
+	cmd.Flags().BoolVarP(&use, "use", "u", false, "Make the new vault the current one")
+
+	return cmd
+}
+
+func newVaultListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List named vaults (* = current)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleVaultList()
+		},
+	}
+}
+
+func newVaultUseCommand() *cobra.Command {
+	var clear bool
+
+	cmd := &cobra.Command{
+		Use:   "use NAME",
+		Short: "Select the vault used by all commands",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if clear {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if clear {
+				return handleVaultUse("")
+			}
+			return handleVaultUse(args[0])
+		},
+	}
+
+	cmd.Flags().BoolVar(&clear, "clear", false, "Stop using a named vault (back to GO_PASSMAN_VAULT / config file / default)")
+
+	return cmd
+}
+
+func newVaultDeleteCommand() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "delete NAME",
+		Short: "Delete a named vault and its backups",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handleVaultDelete(args[0], yes)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation")
+
+	return cmd
+}
+
+func handleVaultCreate(name string, encrypt, use bool) error {
+	path, err := config.NamedVaultPath(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		fmt.Printf("❌ Vault '%s' already exists.\n", name)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create vaults directory: %w", err)
+	}
+
+	storage.SetVaultPath(path, "vault '"+name+"'")
+	vault := models.NewVault()
+	var pwd *string
+	if encrypt {
+		password, err := utils.ReadPasswordConfirm()
+		if err != nil {
+			return err
+		}
+		vault.Encrypted = true
+		pwd = &password
+	}
+	if err := storage.SaveVault(vault, pwd); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Vault '%s' created: %s\n", name, path)
+
+	if use {
+		return handleVaultUse(name)
+	}
+	fmt.Printf("💡 Run 'go-passman vault use %s' to make it the current vault, or pass --vault-name %s.\n", name, name)
+	return nil
+}
+
+func handleVaultList() error {
+	names, err := config.ListVaults()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		fmt.Println("📭 No named vaults yet. Create one with 'go-passman vault create NAME'.")
+		return nil
+	}
+	current, err := config.CurrentVault()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("🗄  Named vaults (* = current):")
+	fmt.Println()
+	for _, name := range names {
+		mark := " "
+		if name == current {
+			mark = "*"
+		}
+		path, _ := config.NamedVaultPath(name)
+		fmt.Printf("  %s %s · %s\n", mark, name, path)
+	}
+	fmt.Println()
+	return nil
+}
+
+func handleVaultUse(name string) error {
+	if name == "" {
+		if err := config.SetCurrentVault(""); err != nil {
+			return err
+		}
+		fmt.Println("✅ No named vault selected.")
+		return nil
+	}
+
+	path, err := config.NamedVaultPath(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		fmt.Printf("❌ Vault '%s' does not exist. Create it with 'go-passman vault create %s'.\n", name, name)
+		os.Exit(1)
+	}
+	if err := config.SetCurrentVault(name); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Now using vault '%s'.\n", name)
+	return nil
+}
+
+func handleVaultDelete(name string, yes bool) error {
+	path, err := config.NamedVaultPath(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		fmt.Printf("❌ Vault '%s' does not exist.\n", name)
+		os.Exit(1)
+	}
+	if !yes && !utils.ConfirmAction(fmt.Sprintf("Delete vault '%s' and all its backups? This cannot be undone.", name)) {
+		fmt.Println("❌ Operation cancelled.")
+		return nil
+	}
+
+	storage.SetVaultPath(path, "vault '"+name+"'")
+	if err := storage.DeleteVault(); err != nil {
+		return err
+	}
+
+	current, err := config.CurrentVault()
+	if err != nil {
+		return err
+	}
+	if current == name {
+		if err := config.SetCurrentVault(""); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("✅ Vault '%s' deleted.\n", name)
+	return nil
+}
@@ -6,11 +6,11 @@ require (
 	github.com/atotto/clipboard v0.1.4
 	github.com/spf13/cobra v1.8.0
 	golang.org/x/crypto v0.27.0
+	golang.org/x/sys v0.25.0
 	golang.org/x/term v0.24.0
 )
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
-	golang.org/x/sys v0.25.0 // indirect
 )
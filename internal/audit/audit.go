@@ -0,0 +1,147 @@
+// Package audit inspects the passwords in a vault and reports problems without revealing them.
+package audit
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"go-passman/internal/models"
+	"go-passman/internal/utils"
+)
+
+// DefaultMinScore is the weakest score that does not count as weak.
+const DefaultMinScore = utils.ScoreFair
+
+// WeakEntry is an entry whose password scored below the audit threshold.
+type WeakEntry struct {
+	Service  string
+	Strength utils.Strength
+}
+
+// Weak returns the entries whose password scores below minScore, weakest first.
+// The service name and login are treated as guessable words.
+func Weak(entries map[string]models.PasswordEntry, minScore int) []WeakEntry {
+	var weak []WeakEntry
+	for service, entry := range entries {
+		s := utils.EstimateStrength(entry.Password, service, entry.Login)
+		if s.Score < minScore {
+			weak = append(weak, WeakEntry{Service: service, Strength: s})
+		}
+	}
+	sort.Slice(weak, func(i, j int) bool {
+		if weak[i].Strength.Bits != weak[j].Strength.Bits {
+			return weak[i].Strength.Bits < weak[j].Strength.Bits
+		}
+		return weak[i].Service < weak[j].Service
+	})
+	return weak
+}
+
+// ReuseGroup is a set of entries sharing a password. Exact is false when the passwords
+// only differ by trailing digits (e.g. "summer1" and "summer2").
+type ReuseGroup struct {
+	Services []string
+	Exact    bool
+}
+
+// minReuseStem is the shortest password stem (without trailing digits) compared for near-reuse;
+// shorter stems such as the empty stem of an all-digit PIN only count when identical.
+const minReuseStem = 4
+
+// reuseKey returns the password without trailing digits, or the whole password when the stem is too short.
+func reuseKey(password string) string {
+	stem := strings.TrimRight(password, "0123456789")
+	if len(stem) < minReuseStem {
+		return password
+	}
+	return stem
+}
+
+// Reuse groups entries with identical or near-identical passwords. Groups and the services
+// in them are sorted by name; exact groups come first. Empty passwords are ignored.
+func Reuse(entries map[string]models.PasswordEntry) []ReuseGroup {
+	byKey := make(map[string][]string)
+	for service, entry := range entries {
+		if entry.Password == "" {
+			continue
+		}
+		key := reuseKey(entry.Password)
+		byKey[key] = append(byKey[key], service)
+	}
+
+	var groups []ReuseGroup
+	for _, services := range byKey {
+		if len(services) < 2 {
+			continue
+		}
+		sort.Strings(services)
+		exact := true
+		for _, s := range services[1:] {
+			if entries[s].Password != entries[services[0]].Password {
+				exact = false
+				break
+			}
+		}
+		groups = append(groups, ReuseGroup{Services: services, Exact: exact})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Exact != groups[j].Exact {
+			return groups[i].Exact
+		}
+		return groups[i].Services[0] < groups[j].Services[0]
+	})
+	return groups
+}
+
+// Reused returns the set of services whose password is shared (exactly or nearly) with another entry.
+func Reused(entries map[string]models.PasswordEntry) map[string]bool {
+	reused := make(map[string]bool)
+	for _, g := range Reuse(entries) {
+		for _, s := range g.Services {
+			reused[s] = true
+		}
+	}
+	return reused
+}
+
+// DefaultDueWithin is how far ahead 'due', 'status' and the web list look for passwords about to expire.
+const DefaultDueWithin = 14 * 24 * time.Hour
+
+// DueEntry is an entry whose password is expired or must be changed soon.
+type DueEntry struct {
+	Service string
+	DueAt   time.Time // zero when the rotation interval has no known starting point
+	Expired bool
+}
+
+// Due returns the entries whose password is expired at now or due within the given duration, soonest first.
+func Due(entries map[string]models.PasswordEntry, now time.Time, within time.Duration) []DueEntry {
+	var due []DueEntry
+	for service, entry := range entries {
+		at, ok := entry.DueAt()
+		if !ok || at.After(now.Add(within)) {
+			continue
+		}
+		due = append(due, DueEntry{Service: service, DueAt: at, Expired: !at.After(now)})
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].DueAt.Equal(due[j].DueAt) {
+			return due[i].DueAt.Before(due[j].DueAt)
+		}
+		return due[i].Service < due[j].Service
+	})
+	return due
+}
+
+// CountDue splits the result of Due into expired and soon-due counts.
+func CountDue(due []DueEntry) (expired, soon int) {
+	for _, d := range due {
+		if d.Expired {
+			expired++
+		} else {
+			soon++
+		}
+	}
+	return expired, soon
+}
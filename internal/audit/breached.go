@@ -0,0 +1,190 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-passman/internal/models"
+)
+
+// rangePrefixLen is the length of the hash prefix that names a file in a range directory.
+const rangePrefixLen = 5
+
+// HIBP looks up passwords in a downloaded Have I Been Pwned SHA-1 dump, without network access.
+// The dump is either one file ordered by hash (lines "HASH:COUNT") or a directory of range files
+// as written by the HIBP downloader ("ABCDE.txt" holding lines "SUFFIX:COUNT" for hashes starting with ABCDE).
+type HIBP struct {
+	path string
+	dir  bool
+	file *os.File
+	size int64
+}
+
+// OpenHIBP opens an ordered hash file or a range directory.
+func OpenHIBP(path string) (*HIBP, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open HIBP data: %w", err)
+	}
+	if info.IsDir() {
+		return &HIBP{path: path, dir: true}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open HIBP data: %w", err)
+	}
+	return &HIBP{path: path, file: f, size: info.Size()}, nil
+}
+
+// Close releases the hash file.
+func (h *HIBP) Close() error {
+	if h.file == nil {
+		return nil
+	}
+	return h.file.Close()
+}
+
+// Count returns how often password appears in the dump (0 = not found).
+func (h *HIBP) Count(password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	if h.dir {
+		return h.countInRange(hash)
+	}
+	return h.countInFile(hash)
+}
+
+// countInRange scans the range file for the hash prefix; range files are small (~1000 lines).
+func (h *HIBP) countInRange(hash string) (int, error) {
+	prefix, suffix := hash[:rangePrefixLen], hash[rangePrefixLen:]
+	f, err := os.Open(filepath.Join(h.path, prefix+".txt"))
+	if os.IsNotExist(err) {
+		f, err = os.Open(filepath.Join(h.path, prefix))
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to open HIBP range file %s: %w", prefix, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lineHash, count := parseHashLine(scanner.Text())
+		if lineHash == suffix {
+			return count, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read HIBP range file %s: %w", prefix, err)
+	}
+	return 0, nil
+}
+
+// countInFile binary-searches the ordered hash file by byte offset, reading one line per step,
+// so lookups stay fast on multi-GB files without loading them.
+func (h *HIBP) countInFile(hash string) (int, error) {
+	lo, hi := int64(0), h.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		start, line, err := h.lineFrom(mid)
+		if err != nil {
+			return 0, err
+		}
+		if start >= hi {
+			hi = mid
+			continue
+		}
+		lineHash, count := parseHashLine(line)
+		switch {
+		case lineHash == hash:
+			return count, nil
+		case lineHash < hash:
+			lo = start + int64(len(line)) + 1
+		default:
+			hi = mid
+		}
+	}
+	return 0, nil
+}
+
+// lineFrom returns the first line that starts at or after offset, with its start offset.
+// At the end of the file the start is h.size.
+func (h *HIBP) lineFrom(offset int64) (int64, string, error) {
+	start := offset
+	if offset > 0 {
+		start = offset - 1 // the line starts at offset if the previous byte is a newline
+	}
+	r := bufio.NewReaderSize(io.NewSectionReader(h.file, start, h.size-start), 256)
+	if offset > 0 {
+		skipped, err := r.ReadString('\n')
+		start += int64(len(skipped))
+		if err == io.EOF {
+			return h.size, "", nil
+		}
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to read HIBP file: %w", err)
+		}
+	}
+	line, err := r.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return 0, "", fmt.Errorf("failed to read HIBP file: %w", err)
+	}
+	if line == "" {
+		return h.size, "", nil
+	}
+	return start, strings.TrimSuffix(line, "\n"), nil
+}
+
+// parseHashLine splits "HASH:COUNT" (count optional, CRLF tolerated) into an upper-case hash and count.
+func parseHashLine(line string) (string, int) {
+	line = strings.TrimRight(line, "\r")
+	hash, countStr, _ := strings.Cut(line, ":")
+	count, err := strconv.Atoi(strings.TrimSpace(countStr))
+	if err != nil || count < 1 {
+		count = 1
+	}
+	return strings.ToUpper(strings.TrimSpace(hash)), count
+}
+
+// BreachedEntry is an entry whose password was found in a breach dump.
+type BreachedEntry struct {
+	Service string
+	Count   int // times the password was seen in breaches
+}
+
+// Breached looks up every entry's password in h and returns the hits, most exposed first.
+// Each distinct password is looked up once.
+func Breached(entries map[string]models.PasswordEntry, h *HIBP) ([]BreachedEntry, error) {
+	counts := make(map[string]int)
+	var hits []BreachedEntry
+	for service, entry := range entries {
+		if entry.Password == "" {
+			continue
+		}
+		count, seen := counts[entry.Password]
+		if !seen {
+			var err error
+			if count, err = h.Count(entry.Password); err != nil {
+				return nil, err
+			}
+			counts[entry.Password] = count
+		}
+		if count > 0 {
+			hits = append(hits, BreachedEntry{Service: service, Count: count})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Count != hits[j].Count {
+			return hits[i].Count > hits[j].Count
+		}
+		return hits[i].Service < hits[j].Service
+	})
+	return hits, nil
+}
@@ -0,0 +1,183 @@
+package audit
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"go-passman/internal/models"
+)
+
+// breachCounts are the passwords of the test dumps and how often they were seen.
+var breachCounts = map[string]int{
+	"password": 3861493,
+	"123456":   37359195,
+	"hunter2":  24230,
+	"letmein":  1,
+}
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// writeHashFile writes an ordered "HASH:COUNT" dump holding breachCounts and filler hashes.
+func writeHashFile(t *testing.T, lineEnd string) string {
+	t.Helper()
+	var lines []string
+	for pw, count := range breachCounts {
+		lines = append(lines, fmt.Sprintf("%s:%d", sha1Hex(pw), count))
+	}
+	for i := 0; i < 500; i++ {
+		lines = append(lines, fmt.Sprintf("%s:%d", sha1Hex(fmt.Sprintf("filler-%d", i)), i+1))
+	}
+	sort.Strings(lines)
+	path := filepath.Join(t.TempDir(), "pwned-passwords-sha1-ordered-by-hash.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, lineEnd)+lineEnd), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writeRangeDir writes a range directory as the HIBP downloader does: ABCDE.txt with "SUFFIX:COUNT" lines.
+func writeRangeDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	ranges := make(map[string][]string)
+	for pw, count := range breachCounts {
+		hash := sha1Hex(pw)
+		prefix := hash[:rangePrefixLen]
+		ranges[prefix] = append(ranges[prefix], fmt.Sprintf("%s:%d", hash[rangePrefixLen:], count))
+		// other hashes of the same range
+		ranges[prefix] = append(ranges[prefix], fmt.Sprintf("%s:7", strings.Repeat("0", len(hash)-rangePrefixLen)))
+	}
+	for prefix, lines := range ranges {
+		sort.Strings(lines)
+		data := strings.Join(lines, "\r\n") + "\r\n"
+		if err := os.WriteFile(filepath.Join(dir, prefix+".txt"), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestHIBPCount(t *testing.T) {
+	sources := []struct {
+		name string
+		path func(t *testing.T) string
+	}{
+		{"ordered file", func(t *testing.T) string { return writeHashFile(t, "\n") }},
+		{"ordered file with CRLF", func(t *testing.T) string { return writeHashFile(t, "\r\n") }},
+		{"range directory", writeRangeDir},
+	}
+	for _, src := range sources {
+		t.Run(src.name, func(t *testing.T) {
+			h, err := OpenHIBP(src.path(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer h.Close()
+			for pw, want := range breachCounts {
+				got, err := h.Count(pw)
+				if err != nil {
+					t.Fatalf("Count(%q): %v", pw, err)
+				}
+				if got != want {
+					t.Errorf("Count(%q) = %d, want %d", pw, got, want)
+				}
+			}
+			if !h.dir {
+				// every filler hash is found too, including the first and last lines
+				for i := 0; i < 500; i++ {
+					if got, err := h.Count(fmt.Sprintf("filler-%d", i)); err != nil || got != i+1 {
+						t.Fatalf("Count(filler-%d) = %d, %v, want %d", i, got, err, i+1)
+					}
+				}
+				if got, err := h.Count("not in the dump"); err != nil || got != 0 {
+					t.Errorf("Count of an unknown password = %d, %v, want 0", got, err)
+				}
+			}
+		})
+	}
+}
+
+func TestHIBPRangeLookup(t *testing.T) {
+	dir := writeRangeDir(t)
+	h, err := OpenHIBP(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	// Serve the range of "password" (without .txt) as the range of another hash, which it does not list
+	hash := sha1Hex("password")
+	other := "other-password"
+	otherHash := sha1Hex(other)
+	if err := os.Rename(filepath.Join(dir, hash[:rangePrefixLen]+".txt"), filepath.Join(dir, otherHash[:rangePrefixLen])); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := h.Count(other); err != nil || got != 0 {
+		t.Errorf("Count of a hash missing from its range file (without .txt) = %d, %v, want 0", got, err)
+	}
+
+	// The range file of "password" is gone now
+	if _, err := h.Count("password"); err == nil {
+		t.Error("Count without the range file succeeded")
+	}
+}
+
+func TestParseHashLine(t *testing.T) {
+	tests := []struct {
+		line  string
+		hash  string
+		count int
+	}{
+		{"ABCDEF:12", "ABCDEF", 12},
+		{"abcdef:12\r", "ABCDEF", 12},
+		{"ABCDEF", "ABCDEF", 1},
+		{"ABCDEF:x", "ABCDEF", 1},
+		{" ABCDEF : 3 ", "ABCDEF", 3},
+	}
+	for _, tt := range tests {
+		hash, count := parseHashLine(tt.line)
+		if hash != tt.hash || count != tt.count {
+			t.Errorf("parseHashLine(%q) = %q, %d, want %q, %d", tt.line, hash, count, tt.hash, tt.count)
+		}
+	}
+}
+
+func TestBreached(t *testing.T) {
+	h, err := OpenHIBP(writeHashFile(t, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	entries := map[string]models.PasswordEntry{
+		"bank":    {Password: "correct horse battery staple"},
+		"forum":   {Password: "123456"},
+		"old":     {Password: "password"},
+		"router":  {Password: "password"},
+		"game":    {Password: "letmein"},
+		"no-pass": {},
+	}
+	got, err := Breached(entries, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []BreachedEntry{
+		{Service: "forum", Count: 37359195},
+		{Service: "old", Count: 3861493},
+		{Service: "router", Count: 3861493},
+		{Service: "game", Count: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Breached = %+v, want %+v", got, want)
+	}
+}
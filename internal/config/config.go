@@ -0,0 +1,206 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Config holds the settings read from the config file.
+type Config struct {
+	// Vault is the vault file path (absolute; relative paths in the file are resolved against the config directory)
+	Vault string
+}
+
+// Dir returns the go-passman config directory: $XDG_CONFIG_HOME/go-passman (~/.config/go-passman) on Linux,
+// %AppData%\go-passman on Windows, ~/Library/Application Support/go-passman on macOS.
+func Dir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find config directory: %w", err)
+	}
+	return filepath.Join(base, "go-passman"), nil
+}
+
+// Path returns the path of the config file.
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config"), nil
+}
+
+// Load reads the config file. A missing file is not an error and gives an empty Config.
+//
+// The file has one "key = value" setting per line; empty lines and lines starting with # are ignored:
+//
+//	# go-passman config
+//	vault = ~/secrets/vault.json
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	defer f.Close()
+
+	cfg := &Config{}
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected key = value", path, lineNum)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch key {
+		case "vault":
+			cfg.Vault, err = ExpandPath(value, filepath.Dir(path))
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, lineNum, err)
+			}
+		default:
+			return nil, fmt.Errorf("%s:%d: unknown setting %q", path, lineNum, key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	return cfg, nil
+}
+
+// ExpandPath expands a leading ~ to the home directory and makes path absolute relative to base.
+func ExpandPath(path, base string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to expand ~: %w", err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(base, path)
+	}
+	return filepath.Clean(path), nil
+}
+
+// vaultNameRe restricts named vaults to names that are safe as file names on every platform.
+var vaultNameRe = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+// ValidateVaultName checks that name can be used as a named vault.
+func ValidateVaultName(name string) error {
+	if !vaultNameRe.MatchString(name) || len(name) > 64 {
+		return fmt.Errorf("invalid vault name %q: use letters, digits, '.', '_' and '-' (max 64)", name)
+	}
+	return nil
+}
+
+// VaultsDir returns the directory holding named vaults (<config dir>/vaults).
+func VaultsDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "vaults"), nil
+}
+
+// NamedVaultPath returns the file of the named vault (<config dir>/vaults/<name>.json).
+func NamedVaultPath(name string) (string, error) {
+	if err := ValidateVaultName(name); err != nil {
+		return "", err
+	}
+	dir, err := VaultsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// ListVaults returns the names of all named vaults, sorted.
+func ListVaults() ([]string, error) {
+	dir, err := VaultsDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vaults: %w", err)
+	}
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		name := strings.TrimSuffix(f.Name(), ".json")
+		if f.IsDir() || name == f.Name() || ValidateVaultName(name) != nil {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// currentPath returns the file holding the name of the current vault.
+func currentPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "current"), nil
+}
+
+// CurrentVault returns the name selected with 'vault use', or "" if none.
+func CurrentVault() (string, error) {
+	path, err := currentPath()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read current vault: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// SetCurrentVault makes name the current vault; an empty name clears the selection.
+func SetCurrentVault(name string) error {
+	path, err := currentPath()
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to clear current vault: %w", err)
+		}
+		return nil
+	}
+	if err := ValidateVaultName(name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(name+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write current vault: %w", err)
+	}
+	return nil
+}
@@ -7,8 +7,11 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 
 	"crypto/sha256"
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/pbkdf2"
 )
 
@@ -17,24 +20,112 @@ const (
 	saltLen          = 16
 	nonceLen         = 12
 	keyLen           = 32
+
+	// headerPrefix marks a versioned vault blob: $gopassman$v=2$<kdf>$<params>$<base64 data>.
+	// Blobs without it are legacy v1 (PBKDF2-SHA256, bare base64 salt|nonce|ciphertext).
+	headerPrefix  = "$gopassman$"
+	formatVersion = 2
 )
 
-// deriveKeyFromPassword derives an encryption key from a password
-func deriveKeyFromPassword(password string, salt []byte) [keyLen]byte {
+// Upper bounds of the Argon2id parameters. They are checked before a key is derived, so a corrupted or
+// tampered header cannot make decryption allocate unbounded memory or run for hours.
+const (
+	MaxMemoryKiB   = 4 * 1024 * 1024 // 4 GiB
+	MaxTime        = 100
+	MaxParallelism = 64
+)
+
+// KDF algorithm names as recorded in the vault header
+const (
+	KDFPBKDF2   = "pbkdf2-sha256"
+	KDFArgon2id = "argon2id"
+)
+
+// KDFParams describes how the encryption key is derived from the master password.
+// Memory is in KiB and only applies to Argon2id; for PBKDF2 Time holds the iteration count.
+type KDFParams struct {
+	Algorithm   string
+	Memory      uint32
+	Time        uint32
+	Parallelism uint8
+}
+
+// DefaultKDFParams returns the Argon2id parameters used for new vaults (RFC 9106: 64 MiB, 3 passes, 4 lanes).
+func DefaultKDFParams() KDFParams {
+	return KDFParams{
+		Algorithm:   KDFArgon2id,
+		Memory:      64 * 1024,
+		Time:        3,
+		Parallelism: 4,
+	}
+}
+
+// Validate checks that the parameters can be used to encrypt a vault, and are within the bounds
+// accepted when decrypting one.
+func (p KDFParams) Validate() error {
+	if p.Algorithm != KDFArgon2id {
+		return fmt.Errorf("unsupported KDF for encryption: %q", p.Algorithm)
+	}
+	if p.Time < 1 || p.Time > MaxTime {
+		return fmt.Errorf("argon2id time must be between 1 and %d", MaxTime)
+	}
+	if p.Parallelism < 1 || p.Parallelism > MaxParallelism {
+		return fmt.Errorf("argon2id parallelism must be between 1 and %d", MaxParallelism)
+	}
+	if p.Memory < 8*uint32(p.Parallelism) {
+		return fmt.Errorf("argon2id memory must be at least %d KiB for parallelism %d", 8*uint32(p.Parallelism), p.Parallelism)
+	}
+	if p.Memory > MaxMemoryKiB {
+		return fmt.Errorf("argon2id memory must be at most %d MiB", MaxMemoryKiB/1024)
+	}
+	return nil
+}
+
+// String returns a human-readable description, e.g. "argon2id (m=65536 KiB, t=3, p=4)".
+func (p KDFParams) String() string {
+	if p.Algorithm == KDFPBKDF2 {
+		return fmt.Sprintf("%s (%d iterations, legacy)", p.Algorithm, p.Time)
+	}
+	return fmt.Sprintf("%s (m=%d KiB, t=%d, p=%d)", p.Algorithm, p.Memory, p.Time, p.Parallelism)
+}
+
+// header returns the versioned header for these parameters (also used as GCM additional data).
+func (p KDFParams) header() string {
+	return fmt.Sprintf("%sv=%d$%s$m=%d,t=%d,p=%d$", headerPrefix, formatVersion, p.Algorithm, p.Memory, p.Time, p.Parallelism)
+}
+
+// deriveKey derives an encryption key from a password using the given KDF parameters
+func deriveKey(password string, salt []byte, params KDFParams) [keyLen]byte {
 	var key [keyLen]byte
-	derivedKey := pbkdf2.Key(
-		[]byte(password),
-		salt,
-		pbkdf2Iterations,
-		keyLen,
-		sha256.New,
-	)
+	var derivedKey []byte
+	switch params.Algorithm {
+	case KDFArgon2id:
+		derivedKey = argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Parallelism, keyLen)
+	default:
+		derivedKey = pbkdf2.Key(
+			[]byte(password),
+			salt,
+			pbkdf2Iterations,
+			keyLen,
+			sha256.New,
+		)
+	}
 	copy(key[:], derivedKey)
 	return key
 }
 
-// Encrypt encrypts plaintext with the given password
+// Encrypt encrypts plaintext with the given password using the default KDF parameters
 func Encrypt(password string, plaintext []byte) (string, error) {
+	return EncryptWithParams(password, plaintext, DefaultKDFParams())
+}
+
+// EncryptWithParams encrypts plaintext with the given password, deriving the key with params.
+// The result is a versioned header followed by base64(salt + nonce + ciphertext).
+func EncryptWithParams(password string, plaintext []byte, params KDFParams) (string, error) {
+	if err := params.Validate(); err != nil {
+		return "", err
+	}
+
 	// Generate random salt
 	salt := make([]byte, saltLen)
 	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
@@ -42,18 +133,11 @@ func Encrypt(password string, plaintext []byte) (string, error) {
 	}
 
 	// Derive key from password
-	key := deriveKeyFromPassword(password, salt)
-
-	// Create cipher
-	block, err := aes.NewCipher(key[:])
-	if err != nil {
-		return "", fmt.Errorf("failed to create cipher: %w", err)
-	}
+	key := deriveKey(password, salt, params)
 
-	// Create GCM mode
-	gcm, err := cipher.NewGCM(block)
+	gcm, err := newGCM(key)
 	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %w", err)
+		return "", err
 	}
 
 	// Generate random nonce
@@ -62,20 +146,26 @@ func Encrypt(password string, plaintext []byte) (string, error) {
 		return "", fmt.Errorf("failed to generate nonce: %w", err)
 	}
 
-	// Encrypt
-	ciphertext := gcm.Seal(nil, nonce, plaintext, nil)
+	// Encrypt; the header is authenticated so KDF parameters cannot be tampered with
+	header := params.header()
+	ciphertext := gcm.Seal(nil, nonce, plaintext, []byte(header))
 
 	// Combine salt + nonce + ciphertext
 	data := append(salt, nonce...)
 	data = append(data, ciphertext...)
 
-	// Encode to base64
-	encoded := base64.StdEncoding.EncodeToString(data)
-	return encoded, nil
+	// Encode to base64 behind the header
+	return header + base64.StdEncoding.EncodeToString(data), nil
 }
 
-// Decrypt decrypts ciphertext with the given password
-func Decrypt(password string, dataB64 string) ([]byte, error) {
+// Decrypt decrypts ciphertext with the given password. Both versioned (Argon2id) and
+// legacy (PBKDF2) vault blobs are accepted.
+func Decrypt(password string, blob string) ([]byte, error) {
+	params, header, dataB64, err := splitHeader(blob)
+	if err != nil {
+		return nil, err
+	}
+
 	// Decode from base64
 	data, err := base64.StdEncoding.DecodeString(dataB64)
 	if err != nil {
@@ -93,8 +183,85 @@ func Decrypt(password string, dataB64 string) ([]byte, error) {
 	ciphertext := data[saltLen+nonceLen:]
 
 	// Derive key
-	key := deriveKeyFromPassword(password, salt)
+	key := deriveKey(password, salt, params)
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decrypt (legacy blobs have no header, so no additional data)
+	var additional []byte
+	if header != "" {
+		additional = []byte(header)
+	}
+	plaintext, err := gcm.Open(nil, nonceBytes, ciphertext, additional)
+	if err != nil {
+		return nil, fmt.Errorf("decryption failed. incorrect password or corrupted data")
+	}
+
+	return plaintext, nil
+}
+
+// ParseParams returns the KDF parameters recorded in an encrypted blob without decrypting it.
+// Legacy blobs report PBKDF2-SHA256.
+func ParseParams(blob string) (KDFParams, error) {
+	params, _, _, err := splitHeader(blob)
+	return params, err
+}
+
+// splitHeader separates the versioned header from the base64 payload.
+func splitHeader(blob string) (params KDFParams, header string, dataB64 string, err error) {
+	blob = strings.TrimSpace(blob)
+	if !strings.HasPrefix(blob, headerPrefix) {
+		return KDFParams{Algorithm: KDFPBKDF2, Time: pbkdf2Iterations}, "", blob, nil
+	}
+
+	// $gopassman$v=2$argon2id$m=...,t=...,p=...$data
+	parts := strings.SplitN(strings.TrimPrefix(blob, headerPrefix), "$", 4)
+	if len(parts) != 4 {
+		return params, "", "", fmt.Errorf("malformed vault header")
+	}
+	if parts[0] != fmt.Sprintf("v=%d", formatVersion) {
+		return params, "", "", fmt.Errorf("unsupported vault format %q", parts[0])
+	}
+	if parts[1] != KDFArgon2id {
+		return params, "", "", fmt.Errorf("unsupported KDF %q", parts[1])
+	}
+	params.Algorithm = parts[1]
+	for _, kv := range strings.Split(parts[2], ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return params, "", "", fmt.Errorf("malformed KDF parameter %q", kv)
+		}
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return params, "", "", fmt.Errorf("malformed KDF parameter %q", kv)
+		}
+		switch k {
+		case "m":
+			params.Memory = uint32(n)
+		case "t":
+			params.Time = uint32(n)
+		case "p":
+			if n > 255 {
+				return params, "", "", fmt.Errorf("malformed KDF parameter %q", kv)
+			}
+			params.Parallelism = uint8(n)
+		default:
+			return params, "", "", fmt.Errorf("unknown KDF parameter %q", k)
+		}
+	}
+	if err := params.Validate(); err != nil {
+		return params, "", "", fmt.Errorf("invalid vault header: %w", err)
+	}
 
+	header = blob[:len(blob)-len(parts[3])]
+	return params, header, parts[3], nil
+}
+
+// newGCM creates an AES-256-GCM cipher for the derived key
+func newGCM(key [keyLen]byte) (cipher.AEAD, error) {
 	// Create cipher
 	block, err := aes.NewCipher(key[:])
 	if err != nil {
@@ -106,12 +273,5 @@ func Decrypt(password string, dataB64 string) ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GCM: %w", err)
 	}
-
-	// Decrypt
-	plaintext, err := gcm.Open(nil, nonceBytes, ciphertext, nil)
-	if err != nil {
-		return nil, fmt.Errorf("decryption failed. incorrect password or corrupted data")
-	}
-
-	return plaintext, nil
+	return gcm, nil
 }
@@ -0,0 +1,194 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// testParams keep Argon2id cheap so the tests run fast.
+var testParams = KDFParams{Algorithm: KDFArgon2id, Memory: 64, Time: 1, Parallelism: 1}
+
+// legacyBlob builds a v1 vault blob the way older releases wrote it: PBKDF2-SHA256 with 100k iterations,
+// AES-256-GCM without additional data, bare base64(salt|nonce|ciphertext).
+func legacyBlob(t *testing.T, password string, plaintext []byte) string {
+	t.Helper()
+	salt := []byte("0123456789abcdef")
+	nonce := []byte("nonce-12byte")
+	key := pbkdf2.Key([]byte(password), salt, 100_000, 32, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := append(append(append([]byte{}, salt...), nonce...), gcm.Seal(nil, nonce, plaintext, nil)...)
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func TestDecryptLegacy(t *testing.T) {
+	blob := legacyBlob(t, "master", []byte(`{"entries":{}}`))
+
+	params, err := ParseParams(blob)
+	if err != nil {
+		t.Fatalf("ParseParams: %v", err)
+	}
+	if params.Algorithm != KDFPBKDF2 || params.Time != pbkdf2Iterations {
+		t.Errorf("ParseParams = %+v, want %s with %d iterations", params, KDFPBKDF2, pbkdf2Iterations)
+	}
+
+	got, err := Decrypt("master", blob)
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if string(got) != `{"entries":{}}` {
+		t.Errorf("Decrypt = %q", got)
+	}
+	if _, err := Decrypt("wrong", blob); err == nil {
+		t.Error("Decrypt with the wrong password succeeded")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		plaintext string
+		params    KDFParams
+	}{
+		{"empty", "", testParams},
+		{"json", `{"entries":{"github":{"password":"s3cret"}}}`, testParams},
+		{"two lanes", "x", KDFParams{Algorithm: KDFArgon2id, Memory: 128, Time: 2, Parallelism: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blob, err := EncryptWithParams("master", []byte(tt.plaintext), tt.params)
+			if err != nil {
+				t.Fatalf("EncryptWithParams: %v", err)
+			}
+			if !strings.HasPrefix(blob, tt.params.header()) {
+				t.Errorf("blob %q does not start with the header %q", blob, tt.params.header())
+			}
+			params, err := ParseParams(blob)
+			if err != nil {
+				t.Fatalf("ParseParams: %v", err)
+			}
+			if params != tt.params {
+				t.Errorf("ParseParams = %+v, want %+v", params, tt.params)
+			}
+			got, err := Decrypt("master", blob)
+			if err != nil {
+				t.Fatalf("Decrypt: %v", err)
+			}
+			if string(got) != tt.plaintext {
+				t.Errorf("Decrypt = %q, want %q", got, tt.plaintext)
+			}
+			if _, err := Decrypt("Master", blob); err == nil {
+				t.Error("Decrypt with the wrong password succeeded")
+			}
+		})
+	}
+}
+
+func TestDecryptRejectsTampering(t *testing.T) {
+	blob, err := EncryptWithParams("master", []byte("secret data"), testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := testParams.header()
+	payload := strings.TrimPrefix(blob, header)
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The same ciphertext sealed without the header as additional data
+	key := deriveKey("master", data[:saltLen], testParams)
+	gcm, err := newGCM(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := data[saltLen : saltLen+nonceLen]
+	noAAD := append(append([]byte{}, data[:saltLen+nonceLen]...), gcm.Seal(nil, nonce, []byte("secret data"), nil)...)
+
+	flipped := append([]byte{}, data...)
+	flipped[len(flipped)-1] ^= 0x01
+
+	tests := []struct {
+		name string
+		blob string
+	}{
+		{"ciphertext byte flipped", header + base64.StdEncoding.EncodeToString(flipped)},
+		{"time changed in header", strings.Replace(blob, ",t=1,", ",t=2,", 1)},
+		{"memory changed in header", strings.Replace(blob, "$m=64,", "$m=72,", 1)},
+		{"header stripped", payload},
+		{"sealed without header as additional data", header + base64.StdEncoding.EncodeToString(noAAD)},
+		{"truncated", header + base64.StdEncoding.EncodeToString(data[:saltLen+nonceLen-1])},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Decrypt("master", tt.blob); err == nil {
+				t.Errorf("Decrypt succeeded: %q", got)
+			}
+		})
+	}
+}
+
+func TestParseParamsRejectsBadHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		blob    string
+		wantErr string
+	}{
+		{"memory over the limit", "$gopassman$v=2$argon2id$m=4294967295,t=3,p=4$AAAA", "at most"},
+		{"memory just over the limit", "$gopassman$v=2$argon2id$m=4194305,t=3,p=4$AAAA", "at most"},
+		{"memory too small for lanes", "$gopassman$v=2$argon2id$m=16,t=3,p=4$AAAA", "at least"},
+		{"time zero", "$gopassman$v=2$argon2id$m=65536,t=0,p=4$AAAA", "time"},
+		{"time over the limit", "$gopassman$v=2$argon2id$m=65536,t=101,p=4$AAAA", "time"},
+		{"parallelism over the limit", "$gopassman$v=2$argon2id$m=65536,t=3,p=65$AAAA", "parallelism"},
+		{"parallelism over a byte", "$gopassman$v=2$argon2id$m=65536,t=3,p=256$AAAA", "malformed"},
+		{"memory over 32 bits", "$gopassman$v=2$argon2id$m=4294967296,t=3,p=4$AAAA", "malformed"},
+		{"unknown parameter", "$gopassman$v=2$argon2id$m=65536,t=3,p=4,x=1$AAAA", "unknown"},
+		{"unknown version", "$gopassman$v=3$argon2id$m=65536,t=3,p=4$AAAA", "unsupported vault format"},
+		{"unknown KDF", "$gopassman$v=2$scrypt$m=65536,t=3,p=4$AAAA", "unsupported KDF"},
+		{"missing payload", "$gopassman$v=2$argon2id$m=65536,t=3,p=4", "malformed vault header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseParams(tt.blob)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ParseParams error = %v, want one containing %q", err, tt.wantErr)
+			}
+			if _, err := Decrypt("master", tt.blob); err == nil {
+				t.Error("Decrypt succeeded")
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		params KDFParams
+		ok     bool
+	}{
+		{"defaults", DefaultKDFParams(), true},
+		{"maxima", KDFParams{Algorithm: KDFArgon2id, Memory: MaxMemoryKiB, Time: MaxTime, Parallelism: MaxParallelism}, true},
+		{"pbkdf2 is read only", KDFParams{Algorithm: KDFPBKDF2, Time: pbkdf2Iterations}, false},
+		{"memory over the limit", KDFParams{Algorithm: KDFArgon2id, Memory: MaxMemoryKiB + 1, Time: 3, Parallelism: 4}, false},
+		{"no lanes", KDFParams{Algorithm: KDFArgon2id, Memory: 65536, Time: 3}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.params.Validate(); (err == nil) != tt.ok {
+				t.Errorf("Validate() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
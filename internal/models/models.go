@@ -1,12 +1,236 @@
 package models
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxPasswordHistory is how many previous passwords an entry keeps.
+const MaxPasswordHistory = 10
+
 // PasswordEntry represents a single password entry in the vault
 type PasswordEntry struct {
-	Login     string `json:"login,omitempty"`
-	Host      string `json:"host,omitempty"`
-	Comment   string `json:"comment,omitempty"`
-	Password  string `json:"password"`
-	Encrypted bool   `json:"encrypted"`
+	Login     string                `json:"login,omitempty"`
+	Host      string                `json:"host,omitempty"`
+	Comment   string                `json:"comment,omitempty"`
+	Password  string                `json:"password"`
+	Encrypted bool                  `json:"encrypted"`
+	History   []PasswordHistoryItem `json:"history,omitempty"` // previous passwords, newest first
+
+	// Zero times are unknown (entries created before timestamps were recorded)
+	CreatedAt         time.Time `json:"created_at"`
+	ModifiedAt        time.Time `json:"modified_at"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	AccessedAt        time.Time `json:"accessed_at"` // last copy or reveal
+
+	RotationDays int       `json:"rotation_days,omitempty"` // change the password every N days (0 = no rotation)
+	ExpiresAt    time.Time `json:"expires_at"`              // fixed expiry date (zero = none)
+
+	Fields []CustomField `json:"fields,omitempty"` // named extra values (URL, API key, security answers...)
+
+	Tags   []string `json:"tags,omitempty"`   // lower-case, sorted (see AddTags)
+	Folder string   `json:"folder,omitempty"` // slash-separated path, e.g. "work/servers" (see CleanFolder)
+
+	OTP *OTPKey `json:"otp,omitempty"` // one-time password secret (see package otp)
+}
+
+// OTPKey is a one-time password secret, as found in an otpauth:// URI. Zero values mean the defaults
+// (SHA1, 6 digits, 30 seconds).
+type OTPKey struct {
+	Type      string `json:"type"`   // "totp" or "hotp"
+	Secret    string `json:"secret"` // base32, upper-case, without padding
+	Algorithm string `json:"algorithm,omitempty"`
+	Digits    int    `json:"digits,omitempty"`
+	Period    int    `json:"period,omitempty"`  // seconds (TOTP)
+	Counter   uint64 `json:"counter,omitempty"` // counter of the next code (HOTP)
+	Issuer    string `json:"issuer,omitempty"`
+	Account   string `json:"account,omitempty"`
+}
+
+// CustomField is a named extra value of an entry. Concealed fields are secrets: they are masked
+// wherever the password is.
+type CustomField struct {
+	Name      string `json:"name"`
+	Value     string `json:"value"`
+	Concealed bool   `json:"concealed,omitempty"`
+}
+
+// NormalizeTag returns the stored form of a tag: trimmed and lower-case.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// CleanFolder returns the stored form of a folder path: trimmed, without empty parts or outer slashes.
+func CleanFolder(folder string) string {
+	var parts []string
+	for _, p := range strings.Split(folder, "/") {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// HasTag reports whether the entry has tag (case-insensitive).
+func (e *PasswordEntry) HasTag(tag string) bool {
+	tag = NormalizeTag(tag)
+	for _, t := range e.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTags adds tags the entry does not have yet and reports whether any was added.
+func (e *PasswordEntry) AddTags(tags ...string) bool {
+	added := false
+	for _, t := range tags {
+		if t = NormalizeTag(t); t != "" && !e.HasTag(t) {
+			e.Tags = append(e.Tags, t)
+			added = true
+		}
+	}
+	sort.Strings(e.Tags)
+	return added
+}
+
+// RemoveTags removes tags from the entry and reports whether any was removed.
+func (e *PasswordEntry) RemoveTags(tags ...string) bool {
+	removed := false
+	for _, t := range tags {
+		t = NormalizeTag(t)
+		for i := range e.Tags {
+			if e.Tags[i] == t {
+				e.Tags = append(e.Tags[:i:i], e.Tags[i+1:]...)
+				removed = true
+				break
+			}
+		}
+	}
+	return removed
+}
+
+// InFolder reports whether the entry is in folder or one of its subfolders ("" matches every entry).
+func (e *PasswordEntry) InFolder(folder string) bool {
+	folder = CleanFolder(folder)
+	return folder == "" || strings.EqualFold(e.Folder, folder) ||
+		strings.HasPrefix(strings.ToLower(e.Folder), strings.ToLower(folder)+"/")
+}
+
+// Field returns the custom field called name (case-insensitive), or nil.
+func (e *PasswordEntry) Field(name string) *CustomField {
+	for i := range e.Fields {
+		if strings.EqualFold(e.Fields[i].Name, name) {
+			return &e.Fields[i]
+		}
+	}
+	return nil
+}
+
+// SetField adds a custom field or replaces the value and kind of an existing one.
+func (e *PasswordEntry) SetField(name, value string, concealed bool) {
+	if f := e.Field(name); f != nil {
+		f.Value, f.Concealed = value, concealed
+		return
+	}
+	e.Fields = append(e.Fields, CustomField{Name: name, Value: value, Concealed: concealed})
+}
+
+// RemoveField deletes the custom field called name and reports whether it existed.
+func (e *PasswordEntry) RemoveField(name string) bool {
+	for i := range e.Fields {
+		if strings.EqualFold(e.Fields[i].Name, name) {
+			e.Fields = append(e.Fields[:i:i], e.Fields[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// TimeFields are the names accepted by PasswordEntry.Time, for sorting (list --sort, web list).
+var TimeFields = []string{"created", "modified", "changed", "used"}
+
+// Time returns the timestamp with the given name from TimeFields; ok is false for an unknown name.
+func (e PasswordEntry) Time(name string) (t time.Time, ok bool) {
+	switch name {
+	case "created":
+		return e.CreatedAt, true
+	case "modified":
+		return e.ModifiedAt, true
+	case "changed":
+		return e.PasswordChangedAt, true
+	case "used":
+		return e.AccessedAt, true
+	}
+	return time.Time{}, false
+}
+
+// DueAt returns when the password must be changed: the earlier of ExpiresAt and the last password
+// change plus RotationDays. ok is false when neither is set. A rotation interval counts from CreatedAt
+// when no password change was recorded, and is due at once when that is unknown too.
+func (e PasswordEntry) DueAt() (due time.Time, ok bool) {
+	if e.RotationDays > 0 {
+		base := e.PasswordChangedAt
+		if base.IsZero() {
+			base = e.CreatedAt
+		}
+		if !base.IsZero() {
+			due = base.AddDate(0, 0, e.RotationDays)
+		}
+		ok = true
+	}
+	if !e.ExpiresAt.IsZero() && (!ok || e.ExpiresAt.Before(due)) {
+		due, ok = e.ExpiresAt, true
+	}
+	return due, ok
+}
+
+// Touch records a modification; the first one also sets CreatedAt.
+func (e *PasswordEntry) Touch(now time.Time) {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = now
+	}
+	e.ModifiedAt = now
+}
+
+// PasswordHistoryItem is a previous password of an entry and when it was replaced.
+type PasswordHistoryItem struct {
+	Password  string    `json:"password"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// SetPassword replaces the password and keeps the old one in History (at most MaxPasswordHistory items).
+// Setting the same password again changes nothing.
+func (e *PasswordEntry) SetPassword(password string, now time.Time) {
+	if password == e.Password {
+		return
+	}
+	if e.Password != "" {
+		e.History = append([]PasswordHistoryItem{{Password: e.Password, ChangedAt: now}}, e.History...)
+		if len(e.History) > MaxPasswordHistory {
+			e.History = e.History[:MaxPasswordHistory]
+		}
+	}
+	e.Password = password
+	e.PasswordChangedAt = now
+}
+
+// RestorePassword makes History[i] the current password; the current password moves into History.
+// An item equal to the current password is an error and leaves the entry unchanged.
+func (e *PasswordEntry) RestorePassword(i int, now time.Time) error {
+	if i < 0 || i >= len(e.History) {
+		return fmt.Errorf("history item %d out of range (1-%d)", i+1, len(e.History))
+	}
+	old := e.History[i].Password
+	if old == e.Password {
+		return fmt.Errorf("history item %d is the current password", i+1)
+	}
+	e.History = append(e.History[:i:i], e.History[i+1:]...)
+	e.SetPassword(old, now)
+	return nil
 }
 
 // Vault represents the entire password vault
@@ -15,6 +239,48 @@ type Vault struct {
 	Encrypted bool                     `json:"encrypted"`
 }
 
+// TagCounts returns how many entries carry each tag.
+func (v *Vault) TagCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, e := range v.Entries {
+		for _, t := range e.Tags {
+			counts[t]++
+		}
+	}
+	return counts
+}
+
+// FolderCounts returns how many entries each folder holds, including its subfolders
+// ("work/servers" also counts for "work").
+func (v *Vault) FolderCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, e := range v.Entries {
+		parts := strings.Split(e.Folder, "/")
+		for i := range parts {
+			if e.Folder != "" {
+				counts[strings.Join(parts[:i+1], "/")]++
+			}
+		}
+	}
+	return counts
+}
+
+// Clone returns a deep copy of the vault: changing the copy, its entries or their slices leaves v untouched.
+func (v *Vault) Clone() *Vault {
+	c := &Vault{Entries: make(map[string]PasswordEntry, len(v.Entries)), Encrypted: v.Encrypted}
+	for name, e := range v.Entries {
+		e.History = append([]PasswordHistoryItem(nil), e.History...)
+		e.Fields = append([]CustomField(nil), e.Fields...)
+		e.Tags = append([]string(nil), e.Tags...)
+		if e.OTP != nil {
+			key := *e.OTP
+			e.OTP = &key
+		}
+		c.Entries[name] = e
+	}
+	return c
+}
+
 // NewVault creates a new empty vault
 func NewVault() *Vault {
 	return &Vault{
@@ -0,0 +1,219 @@
+// Package otp generates one-time passwords (RFC 6238 TOTP, RFC 4226 HOTP) from the secrets stored in vault entries.
+package otp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-passman/internal/models"
+)
+
+const (
+	TypeTOTP = "totp"
+	TypeHOTP = "hotp"
+
+	DefaultAlgorithm    = "SHA1"
+	DefaultDigits       = 6
+	DefaultPeriod       = 30 // seconds
+	DefaultResyncWindow = 20 // HOTP counters searched ahead by Resync
+)
+
+// ErrInvalidKey is returned for secrets and URIs that cannot produce codes.
+var ErrInvalidKey = errors.New("invalid OTP key")
+
+var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Parse reads an otpauth://totp/... or otpauth://hotp/... URI, or a bare base32 secret (TOTP with the defaults).
+// The returned key has the secret normalized and all parameters filled in.
+func Parse(s string) (models.OTPKey, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(strings.ToLower(s), "otpauth://") {
+		return Normalize(models.OTPKey{Type: TypeTOTP, Secret: s})
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return models.OTPKey{}, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	key := models.OTPKey{Type: strings.ToLower(u.Host)}
+	q := u.Query()
+	key.Secret = q.Get("secret")
+	key.Algorithm = q.Get("algorithm")
+	key.Issuer = q.Get("issuer")
+	// Label is "Issuer:account" or "account"
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		if key.Issuer == "" {
+			key.Issuer = strings.TrimSpace(issuer)
+		}
+		label = account
+	}
+	key.Account = strings.TrimSpace(label)
+	if v := q.Get("digits"); v != "" {
+		if key.Digits, err = strconv.Atoi(v); err != nil {
+			return models.OTPKey{}, fmt.Errorf("%w: digits %q", ErrInvalidKey, v)
+		}
+	}
+	if v := q.Get("period"); v != "" && key.Type == TypeTOTP {
+		if key.Period, err = strconv.Atoi(v); err != nil {
+			return models.OTPKey{}, fmt.Errorf("%w: period %q", ErrInvalidKey, v)
+		}
+	}
+	if v := q.Get("counter"); v != "" {
+		if key.Counter, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return models.OTPKey{}, fmt.Errorf("%w: counter %q", ErrInvalidKey, v)
+		}
+	}
+	return Normalize(key)
+}
+
+// Normalize checks key and fills in the defaults, so it can be stored and used for codes.
+func Normalize(key models.OTPKey) (models.OTPKey, error) {
+	if key.Type == "" {
+		key.Type = TypeTOTP
+	}
+	if key.Type != TypeTOTP && key.Type != TypeHOTP {
+		return key, fmt.Errorf("%w: unsupported type %q", ErrInvalidKey, key.Type)
+	}
+	key.Secret = strings.ToUpper(strings.NewReplacer(" ", "", "-", "", "=", "").Replace(key.Secret))
+	if key.Secret == "" {
+		return key, fmt.Errorf("%w: empty secret", ErrInvalidKey)
+	}
+	if _, err := b32.DecodeString(key.Secret); err != nil {
+		return key, fmt.Errorf("%w: secret is not base32", ErrInvalidKey)
+	}
+	if key.Algorithm == "" {
+		key.Algorithm = DefaultAlgorithm
+	}
+	key.Algorithm = strings.ToUpper(strings.ReplaceAll(key.Algorithm, "-", ""))
+	if newHash(key.Algorithm) == nil {
+		return key, fmt.Errorf("%w: unsupported algorithm %q (use SHA1, SHA256 or SHA512)", ErrInvalidKey, key.Algorithm)
+	}
+	if key.Digits == 0 {
+		key.Digits = DefaultDigits
+	}
+	if key.Digits < 6 || key.Digits > 8 {
+		return key, fmt.Errorf("%w: %d digits (use 6 to 8)", ErrInvalidKey, key.Digits)
+	}
+	if key.Type == TypeHOTP {
+		key.Period = 0
+		return key, nil
+	}
+	key.Counter = 0
+	if key.Period == 0 {
+		key.Period = DefaultPeriod
+	}
+	if key.Period < 1 {
+		return key, fmt.Errorf("%w: period %d", ErrInvalidKey, key.Period)
+	}
+	return key, nil
+}
+
+// TOTP returns the code of key at t and how long it stays valid.
+func TOTP(key models.OTPKey, t time.Time) (code string, remaining time.Duration, err error) {
+	if key, err = Normalize(key); err != nil {
+		return "", 0, err
+	}
+	period := int64(key.Period)
+	unix := t.Unix()
+	code, err = hotp(key, uint64(unix/period))
+	remaining = time.Duration(period-unix%period) * time.Second
+	return code, remaining, err
+}
+
+// HOTP returns the code of key for its current counter; the caller advances and saves the counter.
+func HOTP(key models.OTPKey) (string, error) {
+	key, err := Normalize(key)
+	if err != nil {
+		return "", err
+	}
+	return hotp(key, key.Counter)
+}
+
+// Resync looks for codes (one, or several consecutive ones from the device) in the window counters starting at
+// the key's counter. It returns the counter following the last matched code, so the next code is not reused.
+func Resync(key models.OTPKey, codes []string, window int) (next uint64, ok bool, err error) {
+	if key, err = Normalize(key); err != nil {
+		return 0, false, err
+	}
+	if len(codes) == 0 {
+		return 0, false, nil
+	}
+	for c := key.Counter; c < key.Counter+uint64(window); c++ {
+		matched := true
+		for i, code := range codes {
+			want, err := hotp(key, c+uint64(i))
+			if err != nil {
+				return 0, false, err
+			}
+			if want != strings.TrimSpace(code) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return c + uint64(len(codes)), true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// hotp computes the RFC 4226 code of a normalized key for counter.
+func hotp(key models.OTPKey, counter uint64) (string, error) {
+	secret, err := b32.DecodeString(key.Secret)
+	if err != nil {
+		return "", fmt.Errorf("%w: secret is not base32", ErrInvalidKey)
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(newHash(key.Algorithm), secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation
+	off := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[off:off+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < key.Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", key.Digits, value%mod), nil
+}
+
+func newHash(algorithm string) func() hash.Hash {
+	switch algorithm {
+	case "SHA1":
+		return sha1.New
+	case "SHA256":
+		return sha256.New
+	case "SHA512":
+		return sha512.New
+	}
+	return nil
+}
+
+// Label describes key for display, e.g. "TOTP GitHub me@example.com, 6 digits, SHA1, 30s".
+func Label(key models.OTPKey) string {
+	var b strings.Builder
+	b.WriteString(strings.ToUpper(key.Type))
+	if key.Issuer != "" || key.Account != "" {
+		b.WriteString(" " + strings.TrimSpace(key.Issuer+" "+key.Account))
+	}
+	fmt.Fprintf(&b, ", %d digits, %s", key.Digits, key.Algorithm)
+	if key.Type == TypeTOTP {
+		fmt.Fprintf(&b, ", %ds", key.Period)
+	} else {
+		fmt.Fprintf(&b, ", counter %d", key.Counter)
+	}
+	return b.String()
+}
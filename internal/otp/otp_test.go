@@ -0,0 +1,171 @@
+package otp
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go-passman/internal/models"
+)
+
+// RFC 4226 and RFC 6238 test secrets, as ASCII
+const (
+	rfcSecretSHA1   = "12345678901234567890"
+	rfcSecretSHA256 = "12345678901234567890123456789012"
+	rfcSecretSHA512 = "1234567890123456789012345678901234567890123456789012345678901234"
+)
+
+func TestHOTPRFC4226(t *testing.T) {
+	// RFC 4226 appendix D
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range want {
+		key := models.OTPKey{Type: TypeHOTP, Secret: b32.EncodeToString([]byte(rfcSecretSHA1)), Counter: uint64(counter)}
+		got, err := HOTP(key)
+		if err != nil {
+			t.Fatalf("HOTP(counter %d): %v", counter, err)
+		}
+		if got != code {
+			t.Errorf("HOTP(counter %d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestTOTPRFC6238(t *testing.T) {
+	// RFC 6238 appendix B (8 digits, 30 s period)
+	tests := []struct {
+		unix      int64
+		algorithm string
+		code      string
+	}{
+		{59, "SHA1", "94287082"},
+		{59, "SHA256", "46119246"},
+		{59, "SHA512", "90693936"},
+		{1111111109, "SHA1", "07081804"},
+		{1111111109, "SHA256", "68084774"},
+		{1111111109, "SHA512", "25091201"},
+		{1111111111, "SHA1", "14050471"},
+		{1111111111, "SHA256", "67062674"},
+		{1111111111, "SHA512", "99943326"},
+		{1234567890, "SHA1", "89005924"},
+		{1234567890, "SHA256", "91819424"},
+		{1234567890, "SHA512", "93441116"},
+		{2000000000, "SHA1", "69279037"},
+		{2000000000, "SHA256", "90698825"},
+		{2000000000, "SHA512", "38618901"},
+		{20000000000, "SHA1", "65353130"},
+		{20000000000, "SHA256", "77737706"},
+		{20000000000, "SHA512", "47863826"},
+	}
+	secrets := map[string]string{"SHA1": rfcSecretSHA1, "SHA256": rfcSecretSHA256, "SHA512": rfcSecretSHA512}
+	for _, tt := range tests {
+		key := models.OTPKey{
+			Type:      TypeTOTP,
+			Secret:    b32.EncodeToString([]byte(secrets[tt.algorithm])),
+			Algorithm: tt.algorithm,
+			Digits:    8,
+		}
+		code, remaining, err := TOTP(key, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("TOTP(%d, %s): %v", tt.unix, tt.algorithm, err)
+		}
+		if code != tt.code {
+			t.Errorf("TOTP(%d, %s) = %s, want %s", tt.unix, tt.algorithm, code, tt.code)
+		}
+		if want := time.Duration(30-tt.unix%30) * time.Second; remaining != want {
+			t.Errorf("TOTP(%d, %s) remaining = %v, want %v", tt.unix, tt.algorithm, remaining, want)
+		}
+	}
+}
+
+func TestResync(t *testing.T) {
+	key := models.OTPKey{Type: TypeHOTP, Secret: b32.EncodeToString([]byte(rfcSecretSHA1))}
+	tests := []struct {
+		name   string
+		codes  []string
+		window int
+		next   uint64
+		ok     bool
+	}{
+		{"one code", []string{"969429"}, 20, 4, true},
+		{"consecutive codes", []string{"969429", "338314"}, 20, 5, true},
+		{"codes out of order", []string{"338314", "969429"}, 20, 0, false},
+		{"outside the window", []string{"520489"}, 5, 0, false},
+		{"no codes", nil, 20, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, ok, err := Resync(key, tt.codes, tt.window)
+			if err != nil {
+				t.Fatalf("Resync: %v", err)
+			}
+			if next != tt.next || ok != tt.ok {
+				t.Errorf("Resync = %d, %v, want %d, %v", next, ok, tt.next, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want models.OTPKey
+	}{
+		{
+			"bare secret",
+			" jbsw y3dp-ehpk 3pxp ",
+			models.OTPKey{Type: TypeTOTP, Secret: "JBSWY3DPEHPK3PXP", Algorithm: "SHA1", Digits: 6, Period: 30},
+		},
+		{
+			"totp uri with issuer in label",
+			"otpauth://totp/GitHub:me@example.com?secret=JBSWY3DPEHPK3PXP&algorithm=sha-256&digits=8&period=60",
+			models.OTPKey{Type: TypeTOTP, Secret: "JBSWY3DPEHPK3PXP", Algorithm: "SHA256", Digits: 8, Period: 60,
+				Issuer: "GitHub", Account: "me@example.com"},
+		},
+		{
+			"issuer parameter wins over label",
+			"otpauth://totp/Label:me?secret=JBSWY3DPEHPK3PXP&issuer=Example",
+			models.OTPKey{Type: TypeTOTP, Secret: "JBSWY3DPEHPK3PXP", Algorithm: "SHA1", Digits: 6, Period: 30,
+				Issuer: "Example", Account: "me"},
+		},
+		{
+			"hotp uri with counter",
+			"OTPAUTH://HOTP/me?secret=JBSWY3DPEHPK3PXP&counter=42",
+			models.OTPKey{Type: TypeHOTP, Secret: "JBSWY3DPEHPK3PXP", Algorithm: "SHA1", Digits: 6, Counter: 42, Account: "me"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"empty", ""},
+		{"not base32", "not-base32!"},
+		{"unsupported type", "otpauth://motp/me?secret=JBSWY3DPEHPK3PXP"},
+		{"unsupported algorithm", "otpauth://totp/me?secret=JBSWY3DPEHPK3PXP&algorithm=MD5"},
+		{"too many digits", "otpauth://totp/me?secret=JBSWY3DPEHPK3PXP&digits=9"},
+		{"digits not a number", "otpauth://totp/me?secret=JBSWY3DPEHPK3PXP&digits=six"},
+		{"negative period", "otpauth://totp/me?secret=JBSWY3DPEHPK3PXP&period=-30"},
+		{"missing secret", "otpauth://totp/me"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.in); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("Parse(%q) error = %v, want ErrInvalidKey", tt.in, err)
+			}
+		})
+	}
+}
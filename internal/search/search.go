@@ -0,0 +1,305 @@
+// Package search finds vault entries by a query over their fields, shared by the CLI and the web UI.
+//
+// A query is a list of words, all of which must match. A word searches the service name, login, host,
+// comment, tags, folder and custom fields; "field:word" searches one field only (see Fields), "-word"
+// or "!word" excludes entries that contain it, and "quoted words" keep their spaces. Name, login and
+// host also match fuzzily (the letters in order, e.g. "gthb" finds "github"). Passwords and concealed
+// custom field values are never searched.
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go-passman/internal/models"
+)
+
+// Fields are the qualifiers a query word can have, e.g. "host:prod".
+var Fields = []string{"name", "login", "host", "comment", "tag", "folder", "field"}
+
+// fieldAliases maps other spellings of a qualifier to one of Fields.
+var fieldAliases = map[string]string{
+	"service": "name",
+	"user":    "login",
+	"tags":    "tag",
+	"note":    "comment",
+}
+
+// fieldWeights rank matches in more specific fields higher.
+var fieldWeights = map[string]int{
+	"name":    4,
+	"login":   2,
+	"host":    2,
+	"tag":     2,
+	"folder":  2,
+	"comment": 1,
+	"field":   1,
+}
+
+// Match qualities, from best to worst (multiplied by the field weight).
+const (
+	scoreExact     = 100
+	scorePrefix    = 80
+	scoreWordStart = 65
+	scoreContains  = 50
+	scoreFuzzyMax  = 30
+)
+
+type term struct {
+	field  string // one of Fields, or "" for all
+	text   string // lower-case
+	negate bool
+}
+
+// Query is a parsed search query. The zero Query matches every entry.
+type Query struct {
+	terms []term
+}
+
+// Result is a matching entry and its score (higher is better).
+type Result struct {
+	Name  string
+	Score int
+}
+
+// Parse parses a query. A qualifier that is not in Fields is searched as part of the word, so
+// "https://host" is a plain word.
+func Parse(query string) Query {
+	var q Query
+	for _, word := range splitWords(query) {
+		t := term{}
+		if strings.HasPrefix(word, "-") || strings.HasPrefix(word, "!") {
+			t.negate = true
+			word = word[1:]
+		}
+		if name, rest, ok := strings.Cut(word, ":"); ok {
+			if f := field(name); f != "" {
+				t.field, word = f, rest
+			}
+		}
+		t.text = strings.ToLower(strings.Trim(word, `"`))
+		if t.text != "" {
+			q.terms = append(q.terms, t)
+		}
+	}
+	return q
+}
+
+// Empty reports whether the query has no words.
+func (q Query) Empty() bool {
+	return len(q.terms) == 0
+}
+
+// Score returns the score of the entry called name, and false when it does not match.
+func (q Query) Score(name string, entry models.PasswordEntry) (int, bool) {
+	total := 0
+	for _, t := range q.terms {
+		score := t.score(name, &entry)
+		if t.negate {
+			if score > 0 {
+				return 0, false
+			}
+			continue
+		}
+		if score == 0 {
+			return 0, false
+		}
+		total += score
+	}
+	return total, true
+}
+
+// Matches reports whether the entry called name matches.
+func (q Query) Matches(name string, entry models.PasswordEntry) bool {
+	_, ok := q.Score(name, entry)
+	return ok
+}
+
+// Rank returns the matching entries, best first; equal scores are sorted by name.
+func (q Query) Rank(entries map[string]models.PasswordEntry) []Result {
+	var results []Result
+	for name, entry := range entries {
+		if score, ok := q.Score(name, entry); ok {
+			results = append(results, Result{Name: name, Score: score})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
+
+// Names returns the names of results, in order.
+func Names(results []Result) []string {
+	names := make([]string, len(results))
+	for i, r := range results {
+		names[i] = r.Name
+	}
+	return names
+}
+
+// score returns the best weighted match of t in the entry, 0 when none. Negated words match
+// without fuzziness, so they only exclude entries that really contain the text.
+func (t term) score(name string, e *models.PasswordEntry) int {
+	fuzzy := !t.negate
+	best := 0
+	try := func(field, value string, fuzzyField bool) {
+		if t.field != "" && t.field != field {
+			return
+		}
+		if s := matchText(t.text, value, fuzzy && fuzzyField) * fieldWeights[field]; s > best {
+			best = s
+		}
+	}
+	try("name", name, true)
+	try("login", e.Login, true)
+	try("host", e.Host, true)
+	try("comment", e.Comment, false)
+	for _, tag := range e.Tags {
+		if t.field == "tag" {
+			// tag:db means the tag db or tags starting with it, not any tag containing it
+			if s := matchText(t.text, tag, false); s >= scorePrefix {
+				best = maxInt(best, s*fieldWeights["tag"])
+			}
+			continue
+		}
+		try("tag", tag, false)
+	}
+	if t.field == "folder" && e.InFolder(t.text) {
+		// folder:work also finds the subfolders of work
+		best = maxInt(best, scoreExact*fieldWeights["folder"])
+	}
+	try("folder", e.Folder, false)
+	for _, f := range e.Fields {
+		try("field", f.Name, false)
+		if !f.Concealed {
+			try("field", f.Value, false)
+		}
+	}
+	return best
+}
+
+// matchText returns how well text (lower-case) matches value, 0 when it does not.
+func matchText(text, value string, fuzzy bool) int {
+	if value == "" {
+		return 0
+	}
+	v := strings.ToLower(value)
+	switch {
+	case v == text:
+		return scoreExact
+	case strings.HasPrefix(v, text):
+		return scorePrefix
+	}
+	if i := strings.Index(v, text); i >= 0 {
+		for ; i >= 0; i = nextIndex(v, text, i) {
+			if isWordStart(v, i) {
+				return scoreWordStart
+			}
+		}
+		return scoreContains
+	}
+	if fuzzy {
+		return fuzzyScore(text, v)
+	}
+	return 0
+}
+
+// fuzzyScore matches the runes of text in order within value; fewer gaps score higher. Shorter texts
+// match too much to be useful.
+func fuzzyScore(text, value string) int {
+	tr := []rune(text)
+	if len(tr) < 3 {
+		return 0
+	}
+	k, first, last := 0, -1, -1
+	for i, r := range []rune(value) {
+		if k < len(tr) && r == tr[k] {
+			if first < 0 {
+				first = i
+			}
+			last = i
+			k++
+		}
+	}
+	if k < len(tr) {
+		return 0
+	}
+	score := scoreFuzzyMax - (last - first + 1 - len(tr))
+	if first == 0 {
+		score += 5
+	}
+	if score < 1 {
+		score = 1
+	}
+	return score
+}
+
+// nextIndex returns the next index of text in v after i, or -1.
+func nextIndex(v, text string, i int) int {
+	j := strings.Index(v[i+1:], text)
+	if j < 0 {
+		return -1
+	}
+	return i + 1 + j
+}
+
+// isWordStart reports whether position i of v starts a word.
+func isWordStart(v string, i int) bool {
+	if i == 0 {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(v[:i])
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// field returns the qualifier name means, or "".
+func field(name string) string {
+	name = strings.ToLower(name)
+	if a, ok := fieldAliases[name]; ok {
+		return a
+	}
+	for _, f := range Fields {
+		if f == name {
+			return f
+		}
+	}
+	return ""
+}
+
+// splitWords splits a query at spaces outside double quotes.
+func splitWords(query string) []string {
+	var words []string
+	var b strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			b.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if b.Len() > 0 {
+				words = append(words, b.String())
+				b.Reset()
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() > 0 {
+		words = append(words, b.String())
+	}
+	return words
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,147 @@
+package search
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"go-passman/internal/models"
+)
+
+var testEntries = map[string]models.PasswordEntry{
+	"github": {Login: "alice", Host: "github.com", Password: "secret-pass", Tags: []string{"dev", "work"}, Folder: "work/code"},
+	"gitlab": {Login: "admin", Host: "prod.gitlab.local", Comment: "old server", Tags: []string{"old"}},
+	"db-prod": {Login: "admin", Host: "db1.prod", Tags: []string{"db", "database"}, Folder: "work/servers",
+		Fields: []models.CustomField{{Name: "port", Value: "5432"}, {Name: "token", Value: "hunter2", Concealed: true}}},
+	"db-test": {Login: "root", Host: "db.test", Tags: []string{"dbtest"}},
+	"mail":    {Login: "bob@example.com", Comment: "my db notes"},
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []term
+	}{
+		{"", nil},
+		{"  git  ", []term{{text: "git"}}},
+		{"host:prod login:Admin", []term{{field: "host", text: "prod"}, {field: "login", text: "admin"}}},
+		{"user:bob service:db tags:x note:y", []term{{field: "login", text: "bob"}, {field: "name", text: "db"},
+			{field: "tag", text: "x"}, {field: "comment", text: "y"}}},
+		{"-tag:old !test", []term{{field: "tag", text: "old", negate: true}, {text: "test", negate: true}}},
+		{`comment:"old server" "my db"`, []term{{field: "comment", text: "old server"}, {text: "my db"}}},
+		{"https://host", []term{{text: "https://host"}}},
+		{"host: -", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := Parse(tt.query).terms; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"db-prod", "db-test", "github", "gitlab", "mail"}},
+		{"git", []string{"github", "gitlab"}},
+		{"GIT", []string{"github", "gitlab"}},
+		{"gthb", []string{"github"}},
+		{"host:prod", []string{"db-prod", "gitlab"}},
+		{"user:admin", []string{"db-prod", "gitlab"}},
+		{"user:admin -tag:old", []string{"db-prod"}},
+		{"!gitlab", []string{"db-prod", "db-test", "github", "mail"}},
+		{"-glb", []string{"db-prod", "db-test", "github", "gitlab", "mail"}},
+		{`comment:"old server"`, []string{"gitlab"}},
+		{`"my db"`, []string{"mail"}},
+		{"tag:db", []string{"db-prod", "db-test"}},
+		{"tag:data", []string{"db-prod"}},
+		{"tag:base", nil},
+		{"folder:work", []string{"db-prod", "github"}},
+		{"folder:work/servers", []string{"db-prod"}},
+		{"field:5432", []string{"db-prod"}},
+		{"field:token", []string{"db-prod"}},
+		{"hunter2", nil},
+		{"secret-pass", nil},
+		{"name:db", []string{"db-prod", "db-test"}},
+		{"db admin", []string{"db-prod"}},
+		{"https://github.com", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q := Parse(tt.query)
+			var got []string
+			for name, entry := range testEntries {
+				if q.Matches(name, entry) {
+					got = append(got, name)
+				}
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%q matches %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// name prefixes tie and sort by name; the comment match of mail ranks last
+		{"db", []string{"db-prod", "db-test", "mail"}},
+		// an exact name beats a name prefix
+		{"db-prod", []string{"db-prod"}},
+		{"prod", []string{"db-prod", "gitlab"}},
+		{"admin", []string{"db-prod", "gitlab"}},
+		{"", []string{"db-prod", "db-test", "github", "gitlab", "mail"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := Names(Parse(tt.query).Rank(testEntries)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Rank(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchText(t *testing.T) {
+	tests := []struct {
+		text, value string
+		fuzzy       bool
+		want        int
+	}{
+		{"git", "Git", false, scoreExact},
+		{"git", "GitHub", false, scorePrefix},
+		{"hub", "git-hub", false, scoreWordStart},
+		{"hub", "github", false, scoreContains},
+		{"gthb", "github", false, 0},
+		{"gthb", "github", true, scoreFuzzyMax - 2 + 5},
+		{"gh", "github", true, 0},
+		{"x", "", false, 0},
+	}
+	for _, tt := range tests {
+		if got := matchText(tt.text, tt.value, tt.fuzzy); got != tt.want {
+			t.Errorf("matchText(%q, %q, %v) = %d, want %d", tt.text, tt.value, tt.fuzzy, got, tt.want)
+		}
+	}
+}
+
+func TestScoreOrder(t *testing.T) {
+	entry := models.PasswordEntry{}
+	var scores []int
+	for _, name := range []string{"mail", "mailbox", "old mail", "gmail", "my-m-a-i-l"} {
+		score, ok := Parse("mail").Score(name, entry)
+		if !ok {
+			t.Fatalf("mail does not match %q", name)
+		}
+		scores = append(scores, score)
+	}
+	if !sort.SliceIsSorted(scores, func(i, j int) bool { return scores[i] > scores[j] }) {
+		t.Errorf("scores %v are not best first (exact, prefix, word start, contains, fuzzy)", scores)
+	}
+}
@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-passman/internal/crypto"
+	"go-passman/internal/models"
+	"os"
+	"time"
+)
+
+// backupCount is how many previous versions of the vault are kept as vault.json.bak.1..N (1 = newest).
+const backupCount = 5
+
+// BackupInfo describes one rotated backup of the vault file.
+type BackupInfo struct {
+	Index     int
+	Path      string
+	ModTime   time.Time
+	Size      int64
+	Encrypted bool
+	Entries   int // number of entries; only known for unencrypted backups
+}
+
+// backupPath returns the path of the n-th backup (1 = newest).
+func backupPath(n int) string {
+	return fmt.Sprintf("%s.bak.%d", vaultPath, n)
+}
+
+// rotateBackups shifts vault.json.bak.1..N-1 up by one and copies the current vault to vault.json.bak.1.
+// The current vault is copied rather than renamed so vault.json exists at every moment.
+// When encrypting is true, plaintext copies are not kept: a plaintext current vault is not backed up
+// and existing plaintext backups are removed, so encrypting the vault leaves no readable passwords behind.
+func rotateBackups(encrypting bool) error {
+	data, err := os.ReadFile(vaultPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read vault for backup: %w", err)
+	}
+	if encrypting && !isEncryptedData(data) {
+		return removePlaintextBackups()
+	}
+
+	if err := os.Remove(backupPath(backupCount)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove oldest backup: %w", err)
+	}
+	for n := backupCount - 1; n >= 1; n-- {
+		if err := os.Rename(backupPath(n), backupPath(n+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate backup %d: %w", n, err)
+		}
+	}
+	if err := writeFileAtomic(backupPath(1), data, 0600); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	return nil
+}
+
+// ListBackups returns the existing backups, newest first.
+func ListBackups() ([]BackupInfo, error) {
+	backups := make([]BackupInfo, 0, backupCount)
+	for n := 1; n <= backupCount; n++ {
+		path := backupPath(n)
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup %d: %w", n, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup %d: %w", n, err)
+		}
+		b := BackupInfo{Index: n, Path: path, ModTime: info.ModTime(), Size: info.Size()}
+		b.Encrypted = isEncryptedData(data)
+		if !b.Encrypted {
+			var vault models.Vault
+			if err := json.Unmarshal(data, &vault); err == nil {
+				b.Entries = len(vault.Entries)
+			}
+		}
+		backups = append(backups, b)
+	}
+	return backups, nil
+}
+
+// RestoreBackup replaces the vault with backup n. The file is copied as is (no password needed);
+// the current vault becomes the newest backup, so a restore can itself be undone.
+func RestoreBackup(n int) error {
+	if n < 1 || n > backupCount {
+		return fmt.Errorf("backup number %d out of range (1-%d)", n, backupCount)
+	}
+	f, err := acquireLock()
+	if err != nil {
+		return err
+	}
+	defer releaseLock(f)
+
+	data, err := os.ReadFile(backupPath(n))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("backup %d does not exist", n)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read backup %d: %w", n, err)
+	}
+
+	if err := rotateBackups(false); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(vaultPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to restore backup %d: %w", n, err)
+	}
+	return nil
+}
+
+// removePlaintextBackups deletes every backup that is not encrypted.
+func removePlaintextBackups() error {
+	for n := 1; n <= backupCount; n++ {
+		data, err := os.ReadFile(backupPath(n))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read backup %d: %w", n, err)
+		}
+		if isEncryptedData(data) {
+			continue
+		}
+		if err := os.Remove(backupPath(n)); err != nil {
+			return fmt.Errorf("failed to remove plaintext backup %d: %w", n, err)
+		}
+	}
+	return nil
+}
+
+// ReencryptBackups is called after the master password changes, with the vault lock held. Backups that
+// open with oldPassword are re-encrypted with newPassword and the current KDF parameters; the others
+// (plaintext, or encrypted with an even older password) are removed, so no backup opens with a previous
+// password and restoring one never brings an old password back. It returns the number of backups removed.
+func ReencryptBackups(oldPassword, newPassword string) (int, error) {
+	removed := 0
+	for n := 1; n <= backupCount; n++ {
+		data, err := os.ReadFile(backupPath(n))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return removed, fmt.Errorf("failed to read backup %d: %w", n, err)
+		}
+		var plaintext []byte
+		if isEncryptedData(data) {
+			plaintext, err = crypto.Decrypt(oldPassword, string(data))
+		}
+		if plaintext == nil || err != nil {
+			if err := os.Remove(backupPath(n)); err != nil {
+				return removed, fmt.Errorf("failed to remove backup %d: %w", n, err)
+			}
+			removed++
+			continue
+		}
+		encrypted, err := crypto.EncryptWithParams(newPassword, plaintext, GetKDFParams())
+		if err != nil {
+			return removed, fmt.Errorf("failed to re-encrypt backup %d: %w", n, err)
+		}
+		if err := writeFileAtomic(backupPath(n), []byte(encrypted), 0600); err != nil {
+			return removed, fmt.Errorf("failed to write backup %d: %w", n, err)
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ErrVaultBusy is returned when another go-passman process holds the vault lock longer than the lock timeout.
+var ErrVaultBusy = errors.New("vault is busy: another go-passman process is modifying it")
+
+// DefaultLockTimeout is how long a mutation waits for another process to release the vault.
+const DefaultLockTimeout = 10 * time.Second
+
+// lockPollInterval is how often a busy lock is retried while waiting.
+const lockPollInterval = 100 * time.Millisecond
+
+var (
+	lockTimeout = DefaultLockTimeout
+	// heldLock is the lock taken by LoadVaultForUpdate; SaveVault reuses it instead of locking again.
+	heldLock *os.File
+)
+
+// SetLockTimeout sets how long to wait for a busy vault (0 = fail immediately).
+func SetLockTimeout(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	lockTimeout = d
+}
+
+// lockPath returns the path of the lock file. The vault itself is replaced by rename on every save,
+// so the lock lives on a separate file that is never removed.
+func lockPath() string {
+	return vaultPath + ".lock"
+}
+
+// acquireLock takes the exclusive advisory lock on the vault, waiting up to lockTimeout.
+func acquireLock() (*os.File, error) {
+	return acquireLockWithin(lockTimeout)
+}
+
+// acquireLockWithin takes the exclusive advisory lock on the vault, waiting up to timeout.
+func acquireLockWithin(timeout time.Duration) (*os.File, error) {
+	f, err := os.OpenFile(lockPath(), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	announced := false
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock vault: %w", err)
+		}
+		if locked {
+			return f, nil
+		}
+		if !time.Now().Before(deadline) {
+			f.Close()
+			return nil, ErrVaultBusy
+		}
+		if !announced {
+			fmt.Fprintf(os.Stderr, "⏳ Vault is in use by another go-passman process, waiting up to %v...\n", timeout)
+			announced = true
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// releaseLock unlocks and closes a lock taken by acquireLock.
+func releaseLock(f *os.File) {
+	unlockFile(f)
+	f.Close()
+}
+
+// Unlock releases the lock taken by LoadVaultForUpdate. It is safe to call when no lock is held.
+// The lock is also released by the OS when the process exits.
+func Unlock() {
+	if heldLock != nil {
+		releaseLock(heldLock)
+		heldLock = nil
+	}
+}
+
+// WithLock runs fn while holding the vault lock; SaveVault calls inside fn reuse it.
+// Use it to check the vault on disk and save it without another process writing in between.
+// Callers must not use WithLock concurrently with LoadVaultForUpdate in the same process.
+func WithLock(fn func() error) error {
+	return withLockWithin(lockTimeout, fn)
+}
+
+// TryWithLock is WithLock without waiting: it returns ErrVaultBusy at once when another process holds the vault.
+func TryWithLock(fn func() error) error {
+	return withLockWithin(0, fn)
+}
+
+func withLockWithin(timeout time.Duration, fn func() error) error {
+	f, err := acquireLockWithin(timeout)
+	if err != nil {
+		return err
+	}
+	heldLock = f
+	defer Unlock()
+	return fn()
+}
@@ -0,0 +1,13 @@
+//go:build !unix && !windows
+
+package storage
+
+import "os"
+
+// tryLockFile is a no-op on platforms without advisory file locks; the vault is not protected there.
+func tryLockFile(f *os.File) (bool, error) {
+	return true, nil
+}
+
+// unlockFile is a no-op on platforms without advisory file locks.
+func unlockFile(f *os.File) {}
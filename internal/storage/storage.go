@@ -1,42 +1,251 @@
 package storage
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"go-passman/internal/config"
 	"go-passman/internal/crypto"
 	"go-passman/internal/models"
 	"go-passman/internal/utils"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sync"
 )
 
-var vaultPath string
+// EnvVault is the environment variable that overrides the vault path.
+const EnvVault = "GO_PASSMAN_VAULT"
 
-// Init initializes the vault path (same directory as executable)
-func Init() error {
-	exePath, err := os.Executable()
+// EnvPassword is the environment variable holding the master password. Commands use it instead of asking;
+// the non-interactive ones (LoadVaultForUpdateNoPrompt) require it for an encrypted vault.
+const EnvPassword = "GO_PASSMAN_PASSWORD"
+
+// ErrPasswordRequired is returned when an encrypted vault is loaded without a password and without prompting.
+var ErrPasswordRequired = errors.New("vault is encrypted: password required")
+
+var (
+	vaultPath string
+	// vaultSource describes where vaultPath came from (reported by path and status).
+	vaultSource string
+	// kdfParams are used when the vault is next encrypted. They follow the parameters of the
+	// loaded vault, so a legacy PBKDF2 vault is transparently upgraded to Argon2id on save.
+	kdfParams = crypto.DefaultKDFParams()
+	// kdfMu guards kdfParams: the web server loads and saves from concurrent requests.
+	kdfMu sync.Mutex
+)
+
+// Init initializes the vault path. Precedence, highest first:
+//  1. flagPath (the --vault flag), if not empty
+//  2. vaultName (the --vault-name flag), if not empty: a named vault, which must exist
+//  3. the GO_PASSMAN_VAULT environment variable
+//  4. the named vault selected with 'vault use'
+//  5. "vault = ..." in the config file (~/.config/go-passman/config)
+//  6. vault.json in the same directory as the executable
+func Init(flagPath, vaultName string) error {
+	cwd, err := os.Getwd()
 	if err != nil {
-		return fmt.Errorf("failed to get executable path: %w", err)
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	// The vault selected with 'vault use' only applies when nothing more specific is given
+	current := ""
+	if flagPath == "" && vaultName == "" && os.Getenv(EnvVault) == "" {
+		if current, err = config.CurrentVault(); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case flagPath != "":
+		if vaultPath, err = config.ExpandPath(flagPath, cwd); err != nil {
+			return err
+		}
+		vaultSource = "--vault flag"
+	case vaultName != "":
+		return initNamedVault(vaultName, "--vault-name flag")
+	case os.Getenv(EnvVault) != "":
+		if vaultPath, err = config.ExpandPath(os.Getenv(EnvVault), cwd); err != nil {
+			return err
+		}
+		vaultSource = EnvVault + " environment variable"
+	case current != "":
+		return initNamedVault(current, "current vault, see 'vault use'")
+	default:
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if cfg.Vault != "" {
+			vaultPath = cfg.Vault
+			cfgPath, _ := config.Path()
+			vaultSource = "config file " + cfgPath
+			break
+		}
+		exePath, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to get executable path: %w", err)
+		}
+		vaultPath = filepath.Join(filepath.Dir(exePath), "vault.json")
+		vaultSource = "default (next to the executable)"
+		return nil
 	}
 
-	exeDir := filepath.Dir(exePath)
-	vaultPath = filepath.Join(exeDir, "vault.json")
+	// A configured vault may live in a directory that does not exist yet
+	if err := os.MkdirAll(filepath.Dir(vaultPath), 0700); err != nil {
+		return fmt.Errorf("failed to create vault directory: %w", err)
+	}
+	return nil
+}
+
+// initNamedVault points the storage at an existing named vault.
+func initNamedVault(name, source string) error {
+	path, err := config.NamedVaultPath(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("vault '%s' does not exist (create it with 'go-passman vault create %s')", name, name)
+	}
+	vaultPath = path
+	vaultSource = fmt.Sprintf("vault '%s' (%s)", name, source)
 	return nil
 }
 
+// SetVaultPath points the storage at path directly (e.g. a named vault being created).
+func SetVaultPath(path, source string) {
+	vaultPath = path
+	vaultSource = source
+}
+
 // GetVaultPath returns the path to the vault file
 func GetVaultPath() string {
 	return vaultPath
 }
 
-// LoadVault loads the vault from disk, decrypting if necessary (prompts for password on terminal).
+// GetVaultSource describes where the vault path came from, e.g. "--vault flag".
+func GetVaultSource() string {
+	return vaultSource
+}
+
+// SetKDFParams sets the key derivation parameters used by the next SaveVault of an encrypted vault.
+func SetKDFParams(params crypto.KDFParams) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	kdfMu.Lock()
+	defer kdfMu.Unlock()
+	kdfParams = params
+	return nil
+}
+
+// GetKDFParams returns the key derivation parameters that the next SaveVault will use.
+func GetKDFParams() crypto.KDFParams {
+	kdfMu.Lock()
+	defer kdfMu.Unlock()
+	return kdfParams
+}
+
+// VaultKDF returns the key derivation parameters recorded in the encrypted vault file.
+func VaultKDF() (crypto.KDFParams, error) {
+	data, err := os.ReadFile(vaultPath)
+	if err != nil {
+		return crypto.KDFParams{}, fmt.Errorf("failed to read vault: %w", err)
+	}
+	return crypto.ParseParams(string(data))
+}
+
+// rememberKDFParams keeps the Argon2id parameters of a successfully decrypted vault for the next save.
+func rememberKDFParams(data []byte) {
+	if params, err := crypto.ParseParams(string(data)); err == nil && params.Algorithm == crypto.KDFArgon2id {
+		kdfMu.Lock()
+		kdfParams = params
+		kdfMu.Unlock()
+	}
+}
+
+// LoadVault loads the vault from disk, decrypting if necessary (with EnvPassword when set, otherwise it asks).
 // When the vault is encrypted, the password used for decryption is returned as second value
 // so callers can pass it to SaveVault when saving (avoids asking for password twice).
 func LoadVault() (*models.Vault, *string, error) {
 	return loadVaultWithPassword(nil, true)
 }
 
+// LoadVaultForUpdate is LoadVault for commands that modify the vault. It takes the cross-process vault lock
+// (waiting up to the lock timeout) and keeps it until Unlock or process exit, so nobody else can change the
+// vault between this load and the following SaveVault calls. The password is asked for before the lock is taken.
+func LoadVaultForUpdate() (*models.Vault, *string, error) {
+	return lockAndLoad(nil, true)
+}
+
+// LoadVaultForUpdateNoPrompt is LoadVaultForUpdate for scripts: it never asks for anything. An encrypted vault is
+// opened with the password from GO_PASSMAN_PASSWORD; without it ErrPasswordRequired is returned.
+func LoadVaultForUpdateNoPrompt() (*models.Vault, *string, error) {
+	return lockAndLoad(envPassword(), false)
+}
+
+// envPassword returns the password from EnvPassword, or nil when it is not set.
+func envPassword() *string {
+	if p, ok := os.LookupEnv(EnvPassword); ok {
+		return &p
+	}
+	return nil
+}
+
+func lockAndLoad(password *string, promptIfEncrypted bool) (*models.Vault, *string, error) {
+	if password == nil && promptIfEncrypted {
+		// Ask before taking the lock, so other processes are not blocked while the user types
+		p, err := readPasswordIfEncrypted()
+		if err != nil {
+			return nil, nil, err
+		}
+		password = p
+	}
+	if heldLock == nil {
+		f, err := acquireLock()
+		if err != nil {
+			return nil, nil, err
+		}
+		heldLock = f
+	}
+	vault, pwd, err := loadVaultWithPassword(password, promptIfEncrypted)
+	if err != nil {
+		Unlock()
+		return nil, nil, err
+	}
+	return vault, pwd, nil
+}
+
+// readPasswordIfEncrypted returns the password from EnvPassword, or asks for it when the vault is encrypted.
+// It returns nil for a missing or unencrypted vault.
+func readPasswordIfEncrypted() (*string, error) {
+	if p := envPassword(); p != nil {
+		return p, nil
+	}
+	data, err := os.ReadFile(vaultPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault: %w", err)
+	}
+	if !isEncryptedData(data) {
+		return nil, nil
+	}
+	prompt := "Vault is encrypted. Please enter your password: "
+	var vault models.Vault
+	if json.Unmarshal(data, &vault) != nil {
+		prompt = "Vault seems encrypted or corrupted. Please enter password: "
+	}
+	pwd, err := utils.ReadPassword(prompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read password: %w", err)
+	}
+	return &pwd, nil
+}
+
 // LoadVaultWithPassword loads the vault using the given password when encrypted.
 // If vault is encrypted and password is nil, returns (nil, nil, err) so the caller can ask for password (e.g. web unlock form).
 // If vault is encrypted and password is provided, uses it and returns (vault, &password, nil).
@@ -45,6 +254,9 @@ func LoadVaultWithPassword(password *string) (*models.Vault, *string, error) {
 }
 
 func loadVaultWithPassword(password *string, promptIfEncrypted bool) (*models.Vault, *string, error) {
+	if password == nil && promptIfEncrypted {
+		password = envPassword()
+	}
 	if _, err := os.Stat(vaultPath); os.IsNotExist(err) {
 		return models.NewVault(), nil, nil
 	}
@@ -70,7 +282,7 @@ func loadVaultWithPassword(password *string, promptIfEncrypted bool) (*models.Va
 				return nil, nil, fmt.Errorf("failed to read password: %w", errPwd)
 			}
 		} else {
-			return nil, nil, fmt.Errorf("vault is encrypted: password required")
+			return nil, nil, ErrPasswordRequired
 		}
 		decrypted, err := crypto.Decrypt(pwd, string(data))
 		if err != nil {
@@ -78,8 +290,9 @@ func loadVaultWithPassword(password *string, promptIfEncrypted bool) (*models.Va
 				fmt.Fprintf(os.Stderr, "Error decrypting vault: %v\n", err)
 				os.Exit(1)
 			}
-			return nil, nil, fmt.Errorf("decryption failed: %w", err)
+			return nil, nil, err
 		}
+		rememberKDFParams(data)
 		if err := json.Unmarshal(decrypted, &vault); err != nil {
 			return nil, nil, fmt.Errorf("failed to parse decrypted vault: %w", err)
 		}
@@ -90,8 +303,9 @@ func loadVaultWithPassword(password *string, promptIfEncrypted bool) (*models.Va
 	if password != nil {
 		decrypted, err := crypto.Decrypt(*password, string(data))
 		if err != nil {
-			return nil, nil, fmt.Errorf("decryption failed: %w", err)
+			return nil, nil, err
 		}
+		rememberKDFParams(data)
 		if err := json.Unmarshal(decrypted, &vault); err != nil {
 			return nil, nil, fmt.Errorf("failed to parse decrypted vault: %w", err)
 		}
@@ -107,16 +321,39 @@ func loadVaultWithPassword(password *string, promptIfEncrypted bool) (*models.Va
 			fmt.Fprintf(os.Stderr, "Error decrypting vault: %v\n", err)
 			os.Exit(1)
 		}
+		rememberKDFParams(data)
 		if err := json.Unmarshal(decrypted, &vault); err != nil {
 			return nil, nil, fmt.Errorf("failed to parse decrypted vault: %w", err)
 		}
 		return &vault, &pwd, nil
 	}
-	return nil, nil, fmt.Errorf("vault is encrypted: password required")
+	return nil, nil, ErrPasswordRequired
 }
 
-// SaveVault saves the vault to disk, encrypting if necessary
+// SaveVault saves the vault to disk, encrypting if necessary.
+// The file is replaced atomically, so plaintext never touches disk for an encrypted vault.
+// Encrypted vaults are always written in the current versioned format (Argon2id).
+// The previous version is kept as vault.json.bak.1 (older ones are rotated up to backupCount).
+// Unless the lock from LoadVaultForUpdate is held, the vault lock is taken for the duration of the write.
 func SaveVault(vault *models.Vault, password *string) error {
+	return saveVault(vault, password, true)
+}
+
+// SaveVaultAccess saves a vault whose only change is bookkeeping such as last-accessed times or HOTP counters.
+// Unlike SaveVault it keeps the backups as they are, so copying passwords does not push real versions out.
+func SaveVaultAccess(vault *models.Vault, password *string) error {
+	return saveVault(vault, password, false)
+}
+
+func saveVault(vault *models.Vault, password *string, backup bool) error {
+	if heldLock == nil {
+		f, err := acquireLock()
+		if err != nil {
+			return err
+		}
+		defer releaseLock(f)
+	}
+
 	vaultJSON, err := json.MarshalIndent(vault, "", "  ")
 	if err != nil {
 		return fmt.Errorf("serialization error: %w", err)
@@ -126,15 +363,25 @@ func SaveVault(vault *models.Vault, password *string) error {
 		if password == nil {
 			return fmt.Errorf("password required for encrypted vault")
 		}
-		encrypted, err := crypto.Encrypt(*password, vaultJSON)
+		encrypted, err := crypto.EncryptWithParams(*password, vaultJSON, GetKDFParams())
 		if err != nil {
 			return fmt.Errorf("encryption error: %w", err)
 		}
-		if err := os.WriteFile(vaultPath, []byte(encrypted), 0600); err != nil {
+		if backup {
+			if err := rotateBackups(true); err != nil {
+				return err
+			}
+		}
+		if err := writeFileAtomic(vaultPath, []byte(encrypted), 0600); err != nil {
 			return fmt.Errorf("failed to write encrypted vault: %w", err)
 		}
 	} else {
-		if err := os.WriteFile(vaultPath, vaultJSON, 0600); err != nil {
+		if backup {
+			if err := rotateBackups(false); err != nil {
+				return err
+			}
+		}
+		if err := writeFileAtomic(vaultPath, vaultJSON, 0600); err != nil {
 			return fmt.Errorf("failed to write vault: %w", err)
 		}
 	}
@@ -142,8 +389,13 @@ func SaveVault(vault *models.Vault, password *string) error {
 	return nil
 }
 
-// OpenInEditor opens the vault in the specified editor
+// OpenInEditor opens the vault in the specified editor. The lock is not held during the editor session;
+// the edited vault is saved under it, unless another process saved the vault in the meantime.
 func OpenInEditor(editor string) error {
+	before, err := Fingerprint()
+	if err != nil {
+		return err
+	}
 	vault, pwd, err := LoadVault()
 	if err != nil {
 		return err
@@ -187,8 +439,51 @@ func OpenInEditor(editor string) error {
 		return fmt.Errorf("invalid JSON in vault file: %w", err)
 	}
 
-	// Save the vault (pass password if vault was encrypted)
-	return SaveVault(vault, pwd)
+	// Save the vault (pass password if vault was encrypted), unless it changed while the editor was open
+	return WithLock(func() error {
+		now, err := Fingerprint()
+		if err != nil {
+			return err
+		}
+		if now != before {
+			return fmt.Errorf("the vault was changed by another process while the editor was open; edits not saved")
+		}
+		return SaveVault(vault, pwd)
+	})
+}
+
+// Fingerprint returns a hash of the vault file contents ("" when the file does not exist).
+// It changes whenever the vault is written, by this or any other process.
+func Fingerprint() (string, error) {
+	data, err := os.ReadFile(vaultPath)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read vault: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// DeleteVault removes the vault file together with its backups and lock file.
+func DeleteVault() error {
+	f, err := acquireLock()
+	if err != nil {
+		return err
+	}
+	defer os.Remove(lockPath())
+	defer releaseLock(f)
+
+	for n := 1; n <= backupCount; n++ {
+		if err := os.Remove(backupPath(n)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove backup %d: %w", n, err)
+		}
+	}
+	if err := os.Remove(vaultPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove vault: %w", err)
+	}
+	return nil
 }
 
 // IsVaultEncrypted checks if the vault is encrypted
@@ -202,10 +497,14 @@ func IsVaultEncrypted() (bool, error) {
 		return false, fmt.Errorf("failed to read vault: %w", err)
 	}
 
+	return isEncryptedData(data), nil
+}
+
+// isEncryptedData reports whether raw vault file contents are encrypted (anything that is not a plaintext vault JSON).
+func isEncryptedData(data []byte) bool {
 	var vault models.Vault
 	if err := json.Unmarshal(data, &vault); err == nil {
-		return vault.Encrypted, nil
+		return vault.Encrypted
 	}
-
-	return true, nil
+	return true
 }
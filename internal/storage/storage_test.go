@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go-passman/internal/crypto"
+	"go-passman/internal/models"
+)
+
+// useTempVault points the storage at a vault file in a fresh directory, with cheap KDF parameters.
+func useTempVault(t *testing.T) {
+	t.Helper()
+	SetVaultPath(filepath.Join(t.TempDir(), "vault.json"), "test")
+	if err := SetKDFParams(crypto.KDFParams{Algorithm: crypto.KDFArgon2id, Memory: 64, Time: 1, Parallelism: 1}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// versionVault returns a vault holding n entries, so each saved version is recognizable by its size.
+func versionVault(n int, encrypted bool) *models.Vault {
+	vault := models.NewVault()
+	vault.Encrypted = encrypted
+	for i := 1; i <= n; i++ {
+		vault.Entries[fmt.Sprintf("s%d", i)] = models.PasswordEntry{Password: "p"}
+	}
+	return vault
+}
+
+// saveVersions saves versions from..to of the vault with password (nil for plaintext).
+func saveVersions(t *testing.T, from, to int, password *string) {
+	t.Helper()
+	for n := from; n <= to; n++ {
+		if err := SaveVault(versionVault(n, password != nil), password); err != nil {
+			t.Fatalf("SaveVault version %d: %v", n, err)
+		}
+	}
+}
+
+// fileVersion returns the entry count of the vault or backup at path, decrypting it with password if needed.
+func fileVersion(t *testing.T, path, password string) int {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isEncryptedData(data) {
+		if data, err = crypto.Decrypt(password, string(data)); err != nil {
+			t.Fatalf("decrypting %s: %v", filepath.Base(path), err)
+		}
+	}
+	var vault models.Vault
+	if err := json.Unmarshal(data, &vault); err != nil {
+		t.Fatal(err)
+	}
+	return len(vault.Entries)
+}
+
+// backupIndexes returns the numbers of the existing backups, newest first.
+func backupIndexes(t *testing.T) []int {
+	t.Helper()
+	backups, err := ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexes := []int{}
+	for _, b := range backups {
+		indexes = append(indexes, b.Index)
+	}
+	return indexes
+}
+
+func TestRotateBackups(t *testing.T) {
+	tests := []struct {
+		name     string
+		saves    int
+		backups  []int // versions in bak.1, bak.2, ...
+		password *string
+	}{
+		{"first save", 1, []int{}, nil},
+		{"two saves", 2, []int{1}, nil},
+		{"full", backupCount + 1, []int{5, 4, 3, 2, 1}, nil},
+		{"oldest dropped", backupCount + 3, []int{7, 6, 5, 4, 3}, nil},
+		{"encrypted", 4, []int{3, 2, 1}, new(string)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTempVault(t)
+			saveVersions(t, 1, tt.saves, tt.password)
+
+			if got := fileVersion(t, GetVaultPath(), ""); got != tt.saves {
+				t.Errorf("vault holds version %d, want %d", got, tt.saves)
+			}
+			got := []int{}
+			for _, n := range backupIndexes(t) {
+				got = append(got, fileVersion(t, backupPath(n), ""))
+			}
+			if !reflect.DeepEqual(got, tt.backups) {
+				t.Errorf("backups hold versions %v, want %v", got, tt.backups)
+			}
+		})
+	}
+}
+
+func TestSaveVaultAccessKeepsBackups(t *testing.T) {
+	useTempVault(t)
+	saveVersions(t, 1, 3, nil)
+	if err := SaveVaultAccess(versionVault(4, false), nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := fileVersion(t, backupPath(1), ""); got != 2 {
+		t.Errorf("bak.1 holds version %d after SaveVaultAccess, want 2", got)
+	}
+	if got := backupIndexes(t); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("backups = %v, want [1 2]", got)
+	}
+}
+
+func TestEncryptingRemovesPlaintextBackups(t *testing.T) {
+	useTempVault(t)
+	saveVersions(t, 1, 3, nil)
+	if got := backupIndexes(t); len(got) != 2 {
+		t.Fatalf("backups = %v before encrypting, want 2", got)
+	}
+
+	password := "master"
+	saveVersions(t, 4, 4, &password)
+	if got := backupIndexes(t); len(got) != 0 {
+		t.Errorf("backups = %v after encrypting, want none", got)
+	}
+
+	saveVersions(t, 5, 5, &password)
+	backups, err := ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 || !backups[0].Encrypted {
+		t.Fatalf("backups = %+v, want one encrypted backup", backups)
+	}
+	if got := fileVersion(t, backupPath(1), password); got != 4 {
+		t.Errorf("bak.1 holds version %d, want 4", got)
+	}
+}
+
+func TestReencryptBackups(t *testing.T) {
+	useTempVault(t)
+	older, old := "older", "old"
+	saveVersions(t, 1, 1, &older)
+	saveVersions(t, 2, 3, &old) // bak.1 = version 2 (old), bak.2 = version 1 (older)
+	// A plaintext backup left by an older release
+	if err := os.WriteFile(backupPath(3), []byte(`{"entries":{},"encrypted":false}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := ReencryptBackups(old, "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 {
+		t.Errorf("ReencryptBackups removed %d backups, want 2", removed)
+	}
+	if got := backupIndexes(t); !reflect.DeepEqual(got, []int{1}) {
+		t.Fatalf("backups = %v, want [1]", got)
+	}
+	if got := fileVersion(t, backupPath(1), "new"); got != 2 {
+		t.Errorf("bak.1 holds version %d, want 2", got)
+	}
+	data, err := os.ReadFile(backupPath(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := crypto.Decrypt(old, string(data)); err == nil {
+		t.Error("bak.1 still opens with the old password")
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	useTempVault(t)
+	saveVersions(t, 1, 3, nil) // vault = 3, bak.1 = 2, bak.2 = 1
+
+	if err := RestoreBackup(2); err != nil {
+		t.Fatal(err)
+	}
+	if got := fileVersion(t, GetVaultPath(), ""); got != 1 {
+		t.Errorf("vault holds version %d after restoring bak.2, want 1", got)
+	}
+	got := []int{}
+	for _, n := range backupIndexes(t) {
+		got = append(got, fileVersion(t, backupPath(n), ""))
+	}
+	// The replaced vault becomes bak.1, so the restore can be undone
+	if want := []int{3, 2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("backups hold versions %v, want %v", got, want)
+	}
+
+	for _, n := range []int{0, backupCount + 1, 4} {
+		if err := RestoreBackup(n); err == nil {
+			t.Errorf("RestoreBackup(%d) succeeded", n)
+		}
+	}
+}
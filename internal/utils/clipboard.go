@@ -1,11 +1,22 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
 
 	"github.com/atotto/clipboard"
 )
 
+// DefaultClearAfter is how long a copied secret stays on the clipboard before it is cleared.
+const DefaultClearAfter = 45 * time.Second
+
 // CopyToClipboard copies text to the system clipboard
 func CopyToClipboard(text string) error {
 	err := clipboard.WriteAll(text)
@@ -14,3 +25,46 @@ func CopyToClipboard(text string) error {
 	}
 	return nil
 }
+
+// ClipboardDigest identifies copied text without keeping it (used to clear only our own copy).
+func ClipboardDigest(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// StartClipboardClear starts this executable with args as a detached process that outlives the command,
+// and hands it the digest of the copied text on stdin (not in args, which other users can see).
+func StartClipboardClear(text string, args ...string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to start clipboard clearing: %w", err)
+	}
+	c := exec.Command(exe, args...)
+	detach(c)
+	stdin, err := c.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to start clipboard clearing: %w", err)
+	}
+	if err := c.Start(); err != nil {
+		return fmt.Errorf("failed to start clipboard clearing: %w", err)
+	}
+	io.WriteString(stdin, ClipboardDigest(text)+"\n")
+	stdin.Close()
+	return c.Process.Release()
+}
+
+// ClearClipboardIf empties the clipboard if it still holds the text with the given digest.
+// It reports whether the clipboard was cleared.
+func ClearClipboardIf(digest string) (bool, error) {
+	current, err := clipboard.ReadAll()
+	if err != nil {
+		return false, fmt.Errorf("failed to read clipboard: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(ClipboardDigest(current)), []byte(strings.TrimSpace(digest))) != 1 {
+		return false, nil
+	}
+	if err := clipboard.WriteAll(""); err != nil {
+		return false, fmt.Errorf("failed to clear clipboard: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,8 @@
+//go:build !unix && !windows
+
+package utils
+
+import "os/exec"
+
+// detach is a no-op where processes cannot be detached; the helper still runs in the background.
+func detach(c *exec.Cmd) {}
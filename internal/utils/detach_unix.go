@@ -0,0 +1,13 @@
+//go:build unix
+
+package utils
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach runs c in its own session, so closing the terminal or Ctrl+C does not stop it.
+func detach(c *exec.Cmd) {
+	c.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
@@ -29,8 +29,9 @@ func ReadInput(prompt string) (string, error) {
 // ReadPassword reads a password without echoing (hidden input).
 // Works on Windows, Linux, and macOS when stdin is a terminal.
 // Restores terminal echo on Ctrl+C so the shell is not left in invisible input mode.
+// The prompt goes to stderr, so the output of commands stays clean when it is captured.
 func ReadPassword(prompt string) (string, error) {
-	fmt.Print(prompt)
+	fmt.Fprint(os.Stderr, prompt)
 
 	fd := int(os.Stdin.Fd())
 	if term.IsTerminal(fd) {
@@ -51,7 +52,7 @@ func ReadPassword(prompt string) (string, error) {
 		}
 
 		bytePassword, err := term.ReadPassword(fd)
-		fmt.Println() // newline after hidden input
+		fmt.Fprintln(os.Stderr) // newline after hidden input
 		if err != nil {
 			return "", err
 		}
@@ -88,9 +89,10 @@ func ReadPasswordConfirm() (string, error) {
 
 const choosePageSize = 25 // max items shown at once when choosing; more than this triggers pagination
 
-// ChooseFromList lets user choose from a list. If filterPrompt is non-empty, asks for a name filter first (substring, Enter = all).
+// ChooseFromList lets user choose from a list. If filterPrompt is non-empty, asks for a filter first (Enter = all):
+// filter returns the matching items in the order to show them (nil = case-insensitive substring of the item).
 // When there are more than choosePageSize items, shows pages (n = next, q = quit).
-func ChooseFromList(items []string, prompt, filterPrompt string) (string, error) {
+func ChooseFromList(items []string, prompt, filterPrompt string, filter func(query string) []string) (string, error) {
 	if len(items) == 0 {
 		return "", fmt.Errorf("no items to choose from")
 	}
@@ -101,12 +103,17 @@ func ChooseFromList(items []string, prompt, filterPrompt string) (string, error)
 		if err != nil {
 			return "", err
 		}
-		filter := strings.TrimSpace(strings.ToLower(filterLine))
-		if filter != "" {
-			filtered := make([]string, 0)
-			for _, item := range items {
-				if strings.Contains(strings.ToLower(item), filter) {
-					filtered = append(filtered, item)
+		query := strings.TrimSpace(filterLine)
+		if query != "" {
+			var filtered []string
+			if filter != nil {
+				filtered = filter(query)
+			} else {
+				sub := strings.ToLower(query)
+				for _, item := range items {
+					if strings.Contains(strings.ToLower(item), sub) {
+						filtered = append(filtered, item)
+					}
 				}
 			}
 			if len(filtered) == 0 {
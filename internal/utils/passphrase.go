@@ -0,0 +1,104 @@
+package utils
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+)
+
+// wordlistData is a diceware word list in the EFF short-list format: 1296 lines "<four dice rolls>\t<word>",
+// so a passphrase can also be built by hand with four dice per word.
+//
+//go:embed wordlist.txt
+var wordlistData string
+
+var (
+	wordlistOnce sync.Once
+	wordlist     []string
+)
+
+// MaxPassphraseWords is the largest word count GeneratePassphrase accepts.
+const MaxPassphraseWords = 64
+
+// words returns the parsed embedded word list.
+func words() []string {
+	wordlistOnce.Do(func() {
+		for _, line := range strings.Split(wordlistData, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 2 {
+				wordlist = append(wordlist, fields[1])
+			}
+		}
+	})
+	return wordlist
+}
+
+// PassphrasePolicy describes how GeneratePassphrase builds a passphrase.
+type PassphrasePolicy struct {
+	Words      int
+	Separator  string
+	Capitalize bool // capitalize the first letter of every word
+	Digit      bool // append a random digit to one random word
+}
+
+// DefaultPassphrasePolicy returns the policy used when no options are given: six words joined by "-".
+func DefaultPassphrasePolicy() PassphrasePolicy {
+	return PassphrasePolicy{
+		Words:     6,
+		Separator: "-",
+	}
+}
+
+// Validate checks that a passphrase can be generated with the policy.
+func (p PassphrasePolicy) Validate() error {
+	if p.Words < 1 || p.Words > MaxPassphraseWords {
+		return fmt.Errorf("word count must be between 1 and %d", MaxPassphraseWords)
+	}
+	return nil
+}
+
+// Entropy returns the strength of a passphrase generated with the policy, in bits.
+// Capitalization is applied to every word, so it adds no entropy.
+func (p PassphrasePolicy) Entropy() float64 {
+	bits := float64(p.Words) * math.Log2(float64(len(words())))
+	if p.Digit {
+		bits += math.Log2(10) + math.Log2(float64(p.Words))
+	}
+	return bits
+}
+
+// GeneratePassphrase generates a diceware passphrase from the embedded word list using crypto/rand.
+func GeneratePassphrase(p PassphrasePolicy) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+
+	list := words()
+	chosen := make([]string, p.Words)
+	for i := range chosen {
+		n, err := randomInt(len(list))
+		if err != nil {
+			return "", err
+		}
+		chosen[i] = list[n]
+		if p.Capitalize {
+			chosen[i] = strings.ToUpper(chosen[i][:1]) + chosen[i][1:]
+		}
+	}
+
+	if p.Digit {
+		i, err := randomInt(len(chosen))
+		if err != nil {
+			return "", err
+		}
+		d, err := randomChar(numbers)
+		if err != nil {
+			return "", err
+		}
+		chosen[i] += string(d)
+	}
+
+	return strings.Join(chosen, p.Separator), nil
+}
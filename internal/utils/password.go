@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"crypto/rand"
 	"fmt"
-	"math/rand"
+	"math"
+	"math/big"
 	"strings"
 )
 
@@ -11,47 +13,195 @@ const (
 	lowercase = "abcdefghijklmnopqrstuvwxyz"
 	uppercase = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
 	special   = "!@#$%^&*()_+-=[]{}|;:,.<>?"
+
+	// ambiguous characters are easy to confuse when a password is read or typed by hand
+	ambiguous = "0O1lI|"
+
+	// MaxPasswordLength is the longest password GeneratePassword produces.
+	MaxPasswordLength = 1024
 )
 
-// GeneratePassword generates a random password
-func GeneratePassword(length int, useNumbers bool, useSpecial bool) string {
-	charset := lowercase + uppercase
+// PasswordPolicy describes how GeneratePassword builds a password.
+type PasswordPolicy struct {
+	Length           int
+	Lower            bool
+	Upper            bool
+	Numbers          bool
+	Special          bool
+	ExcludeAmbiguous bool   // drop 0/O/1/l/I/| from every character set
+	Charset          string // custom character set; when set, the class options above are ignored
+	MinPerClass      int    // minimum number of characters from each selected class
+}
 
-	if useNumbers {
-		charset += numbers
+// DefaultPasswordPolicy returns the policy used when no options are given: 16 characters from all classes.
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		Length:      16,
+		Lower:       true,
+		Upper:       true,
+		Numbers:     true,
+		Special:     true,
+		MinPerClass: 1,
 	}
-	if useSpecial {
-		charset += special
+}
+
+// classes returns the character sets selected by the policy (a custom charset is a single class).
+func (p PasswordPolicy) classes() []string {
+	var sets []string
+	if p.Charset != "" {
+		sets = []string{dedupe(p.Charset)}
+	} else {
+		for _, c := range []struct {
+			on  bool
+			set string
+		}{{p.Lower, lowercase}, {p.Upper, uppercase}, {p.Numbers, numbers}, {p.Special, special}} {
+			if c.on {
+				sets = append(sets, c.set)
+			}
+		}
 	}
+	if p.ExcludeAmbiguous {
+		for i, set := range sets {
+			sets[i] = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(ambiguous, r) {
+					return -1
+				}
+				return r
+			}, set)
+		}
+	}
+	return sets
+}
 
-	password := make([]byte, length)
-	for i := 0; i < length; i++ {
-		password[i] = charset[rand.Intn(len(charset))]
+// Validate checks that a password can be generated with the policy.
+func (p PasswordPolicy) Validate() error {
+	if p.Length < 1 || p.Length > MaxPasswordLength {
+		return fmt.Errorf("password length must be between 1 and %d", MaxPasswordLength)
+	}
+	if p.MinPerClass < 0 {
+		return fmt.Errorf("minimum per class cannot be negative")
+	}
+	classes := p.classes()
+	if len(classes) == 0 {
+		return fmt.Errorf("no character classes selected")
 	}
+	for _, set := range classes {
+		if set == "" {
+			return fmt.Errorf("character set is empty (custom charsets use printable ASCII; ambiguous characters may be excluded)")
+		}
+	}
+	if p.Charset == "" && len(classes)*p.MinPerClass > p.Length {
+		return fmt.Errorf("length %d is too short for %d characters from each of %d classes", p.Length, p.MinPerClass, len(classes))
+	}
+	return nil
+}
 
-	return string(password)
+// Entropy returns the strength of a password generated with the policy, in bits.
+// The guaranteed characters per class slightly reduce it; this is the usual length * log2(charset) estimate.
+func (p PasswordPolicy) Entropy() float64 {
+	n := len(strings.Join(p.classes(), ""))
+	if n == 0 {
+		return 0
+	}
+	return float64(p.Length) * math.Log2(float64(n))
+}
+
+// GeneratePassword generates a random password from crypto/rand.
+// Each selected class contributes at least MinPerClass characters; the rest are drawn from all
+// selected classes, and the result is shuffled so the guaranteed characters are not at fixed positions.
+func GeneratePassword(p PasswordPolicy) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+
+	classes := p.classes()
+	password := make([]byte, 0, p.Length)
+	if p.Charset == "" {
+		for _, set := range classes {
+			for i := 0; i < p.MinPerClass; i++ {
+				c, err := randomChar(set)
+				if err != nil {
+					return "", err
+				}
+				password = append(password, c)
+			}
+		}
+	}
+
+	all := strings.Join(classes, "")
+	for len(password) < p.Length {
+		c, err := randomChar(all)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+
+	// Fisher-Yates shuffle
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+
+	return string(password), nil
+}
+
+// randomInt returns a uniform random integer in [0, n) from crypto/rand.
+func randomInt(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read random data: %w", err)
+	}
+	return int(v.Int64()), nil
+}
+
+// randomChar returns a uniformly chosen byte of set.
+func randomChar(set string) (byte, error) {
+	i, err := randomInt(len(set))
+	if err != nil {
+		return 0, err
+	}
+	return set[i], nil
+}
+
+// dedupe removes repeated characters (so a custom charset like "aab" does not favour 'a')
+// and non-ASCII characters (passwords are built byte by byte).
+func dedupe(set string) string {
+	var b strings.Builder
+	for i := 0; i < len(set); i++ {
+		c := set[i]
+		if c < 0x21 || c > 0x7e || strings.IndexByte(b.String(), c) >= 0 {
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
 }
 
 // ChoosePasswordOptions prompts user for password generation options
-func ChoosePasswordOptions() (int, bool, bool) {
+func ChoosePasswordOptions() PasswordPolicy {
+	policy := DefaultPasswordPolicy()
+
 	var lengthStr string
 	fmt.Print("Enter password length (default 16): ")
 	fmt.Scanln(&lengthStr)
 
-	length := 16
 	if lengthStr != "" {
-		fmt.Sscanf(lengthStr, "%d", &length)
+		fmt.Sscanf(lengthStr, "%d", &policy.Length)
 	}
 
 	var useNumbers string
 	fmt.Print("Include numbers? (y/n, default y): ")
 	fmt.Scanln(&useNumbers)
-	useNumStr := strings.ToLower(useNumbers) != "n"
+	policy.Numbers = strings.ToLower(useNumbers) != "n"
 
 	var useSpecial string
 	fmt.Print("Include special characters? (y/n, default y): ")
 	fmt.Scanln(&useSpecial)
-	useSpecStr := strings.ToLower(useSpecial) != "n"
+	policy.Special = strings.ToLower(useSpecial) != "n"
 
-	return length, useNumStr, useSpecStr
+	return policy
 }
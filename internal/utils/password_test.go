@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+// countIn returns how many characters of s are in set.
+func countIn(s, set string) int {
+	n := 0
+	for _, r := range s {
+		if strings.ContainsRune(set, r) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestGeneratePassword(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  PasswordPolicy
+		classes []string // sets each holding at least MinPerClass characters
+		allowed string   // every character must be in it
+	}{
+		{"default", DefaultPasswordPolicy(), []string{lowercase, uppercase, numbers, special}, lowercase + uppercase + numbers + special},
+		{"three per class", PasswordPolicy{Length: 12, Lower: true, Upper: true, Numbers: true, Special: true, MinPerClass: 3},
+			[]string{lowercase, uppercase, numbers, special}, lowercase + uppercase + numbers + special},
+		{"digits only", PasswordPolicy{Length: 6, Numbers: true, MinPerClass: 6}, []string{numbers}, numbers},
+		{"letters without minimum", PasswordPolicy{Length: 20, Lower: true, Upper: true}, nil, lowercase + uppercase},
+		{"custom charset", PasswordPolicy{Length: 32, Charset: "abc123", MinPerClass: 1}, nil, "abc123"},
+		{"custom charset with duplicates and spaces", PasswordPolicy{Length: 32, Charset: "xx y\tzé"}, nil, "xyz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				pw, err := GeneratePassword(tt.policy)
+				if err != nil {
+					t.Fatalf("GeneratePassword: %v", err)
+				}
+				if len(pw) != tt.policy.Length {
+					t.Fatalf("len(%q) = %d, want %d", pw, len(pw), tt.policy.Length)
+				}
+				if n := countIn(pw, tt.allowed); n != len(pw) {
+					t.Fatalf("%q has %d characters outside %q", pw, len(pw)-n, tt.allowed)
+				}
+				for _, set := range tt.classes {
+					if n := countIn(pw, set); n < tt.policy.MinPerClass {
+						t.Fatalf("%q has %d characters of %q, want at least %d", pw, n, set, tt.policy.MinPerClass)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGeneratePasswordExcludeAmbiguous(t *testing.T) {
+	policies := []PasswordPolicy{
+		{Length: 64, Lower: true, Upper: true, Numbers: true, Special: true, MinPerClass: 4, ExcludeAmbiguous: true},
+		{Length: 64, Charset: "0O1lI|ab", ExcludeAmbiguous: true},
+	}
+	for _, p := range policies {
+		for i := 0; i < 50; i++ {
+			pw, err := GeneratePassword(p)
+			if err != nil {
+				t.Fatalf("GeneratePassword(%+v): %v", p, err)
+			}
+			if strings.ContainsAny(pw, ambiguous) {
+				t.Fatalf("GeneratePassword(%+v) = %q, which has ambiguous characters", p, pw)
+			}
+		}
+	}
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  PasswordPolicy
+		wantErr string
+	}{
+		{"default", DefaultPasswordPolicy(), ""},
+		{"length equals classes times minimum", PasswordPolicy{Length: 8, Lower: true, Upper: true, Numbers: true, Special: true, MinPerClass: 2}, ""},
+		{"length below classes times minimum", PasswordPolicy{Length: 7, Lower: true, Upper: true, Numbers: true, Special: true, MinPerClass: 2}, "too short"},
+		{"custom charset ignores the minimum", PasswordPolicy{Length: 2, Charset: "ab", MinPerClass: 5}, ""},
+		{"zero length", PasswordPolicy{Length: 0, Lower: true}, "between 1"},
+		{"too long", PasswordPolicy{Length: MaxPasswordLength + 1, Lower: true}, "between 1"},
+		{"negative minimum", PasswordPolicy{Length: 8, Lower: true, MinPerClass: -1}, "negative"},
+		{"no classes", PasswordPolicy{Length: 8}, "no character classes"},
+		{"charset of ambiguous characters only", PasswordPolicy{Length: 8, Charset: "0O1lI", ExcludeAmbiguous: true}, "empty"},
+		{"charset without printable ASCII", PasswordPolicy{Length: 8, Charset: " é\t"}, "empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+			if _, err := GeneratePassword(tt.policy); err == nil {
+				t.Error("GeneratePassword succeeded")
+			}
+		})
+	}
+}
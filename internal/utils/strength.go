@@ -0,0 +1,418 @@
+package utils
+
+import (
+	_ "embed"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// commonPasswordsData lists frequently used passwords, most common first (rank = line number).
+//
+//go:embed common_passwords.txt
+var commonPasswordsData string
+
+// Strength scores returned by EstimateStrength, from worst to best.
+const (
+	ScoreVeryWeak = iota
+	ScoreWeak
+	ScoreFair
+	ScoreStrong
+	ScoreVeryStrong
+)
+
+// scoreBits are the lower entropy bounds (bits) of ScoreWeak..ScoreVeryStrong.
+var scoreBits = [...]float64{25, 40, 60, 80}
+
+var scoreLabels = [...]string{"very weak", "weak", "fair", "strong", "very strong"}
+
+// ScoreLabel returns a human-readable name for a strength score, e.g. "weak".
+func ScoreLabel(score int) string {
+	if score < 0 || score >= len(scoreLabels) {
+		return "unknown"
+	}
+	return scoreLabels[score]
+}
+
+// Strength is the result of EstimateStrength.
+type Strength struct {
+	Bits     float64  // estimated log2 of the number of guesses needed to find the password
+	Score    int      // ScoreVeryWeak..ScoreVeryStrong
+	Warnings []string // patterns that make the password easier to guess
+}
+
+// Label returns the name of the score, e.g. "fair".
+func (s Strength) Label() string {
+	return ScoreLabel(s.Score)
+}
+
+// dictEntry is a dictionary word with its rank (1 = guessed first) and the warning shown when it matches.
+type dictEntry struct {
+	rank    int
+	warning string
+}
+
+var (
+	dictOnce   sync.Once
+	dictionary map[string]dictEntry
+)
+
+const (
+	warnCommon    = "contains a commonly used password"
+	warnWord      = "contains a dictionary word"
+	warnUserInput = "contains the service name or login"
+	warnKeyboard  = "contains a keyboard pattern"
+	warnSequence  = "contains a sequence like abc or 123"
+	warnRepeat    = "contains repeated characters"
+	warnYear      = "contains a year"
+	warnShort     = "is shorter than 8 characters"
+)
+
+// loadDictionary builds the ranked dictionary from the common passwords and the passphrase word list.
+func loadDictionary() map[string]dictEntry {
+	dictOnce.Do(func() {
+		dictionary = make(map[string]dictEntry)
+		for i, w := range strings.Fields(commonPasswordsData) {
+			dictionary[strings.ToLower(w)] = dictEntry{rank: i + 1, warning: warnCommon}
+		}
+		for i, w := range words() {
+			if _, exists := dictionary[w]; !exists {
+				dictionary[w] = dictEntry{rank: i + 1, warning: warnWord}
+			}
+		}
+	})
+	return dictionary
+}
+
+// keyboardRows is the US QWERTY layout, unshifted and shifted, used to detect keyboard walks.
+var keyboardRows = [2][4]string{
+	{"`1234567890-=", "qwertyuiop[]\\", "asdfghjkl;'", "zxcvbnm,./"},
+	{"~!@#$%^&*()_+", "QWERTYUIOP{}|", "ASDFGHJKL:\"", "ZXCVBNM<>?"},
+}
+
+type keyPos struct{ row, col int }
+
+var (
+	keyboardOnce sync.Once
+	keyPositions map[byte]keyPos
+)
+
+// keyPosition returns the position of c on the keyboard and whether it needs shift.
+func keyPosition(c byte) (keyPos, bool, bool) {
+	keyboardOnce.Do(func() {
+		keyPositions = make(map[byte]keyPos)
+		for row := range keyboardRows[0] {
+			for col := range keyboardRows[0][row] {
+				keyPositions[keyboardRows[0][row][col]] = keyPos{row, col}
+				keyPositions[keyboardRows[1][row][col]] = keyPos{row, col}
+			}
+		}
+	})
+	pos, ok := keyPositions[c]
+	shifted := ok && strings.IndexByte(strings.Join(keyboardRows[1][:], ""), c) >= 0
+	return pos, shifted, ok
+}
+
+// adjacentKeys reports whether two keys are neighbours on the (row-staggered) keyboard.
+func adjacentKeys(a, b keyPos) bool {
+	switch b.row - a.row {
+	case 0:
+		return b.col == a.col-1 || b.col == a.col+1
+	case -1: // row above is shifted half a key to the left
+		return b.col == a.col || b.col == a.col+1
+	case 1:
+		return b.col == a.col || b.col == a.col-1
+	}
+	return false
+}
+
+// leetReplacer undoes common l33t substitutions (p@ssw0rd -> password).
+var leetReplacer = strings.NewReplacer("4", "a", "@", "a", "3", "e", "1", "i", "!", "i", "0", "o", "$", "s", "5", "s", "7", "t", "+", "t")
+
+var yearRe = regexp.MustCompile(`(19|20)\d\d`)
+
+// match is a guessable pattern covering password[i:j].
+type match struct {
+	i, j    int
+	bits    float64
+	warning string
+}
+
+// EstimateStrength estimates how hard password is to guess, in the spirit of zxcvbn: it finds
+// dictionary words (common passwords, the passphrase word list, l33t and reversed variants, userInputs
+// such as the service name), keyboard walks, sequences, repeats and years, and picks the cheapest
+// way to cover the password with those patterns and brute-forced characters.
+func EstimateStrength(password string, userInputs ...string) Strength {
+	n := len(password)
+	if n == 0 {
+		return Strength{Score: ScoreVeryWeak, Warnings: []string{"is empty"}}
+	}
+
+	matches := dictionaryMatches(password, userInputs)
+	matches = append(matches, keyboardMatches(password)...)
+	matches = append(matches, sequenceMatches(password)...)
+	matches = append(matches, repeatMatches(password)...)
+	for _, loc := range yearRe.FindAllStringIndex(password, -1) {
+		matches = append(matches, match{loc[0], loc[1], math.Log2(120), warnYear})
+	}
+
+	// best[k] is the cheapest cover of password[:k]; each extra pattern costs one bit
+	charBits := math.Log2(float64(poolSize(password)))
+	best := make([]float64, n+1)
+	via := make([]*match, n+1)
+	for k := 1; k <= n; k++ {
+		best[k] = best[k-1] + charBits
+		via[k] = nil
+		for m := range matches {
+			if matches[m].j == k {
+				if cost := best[matches[m].i] + matches[m].bits + 1; cost < best[k] {
+					best[k] = cost
+					via[k] = &matches[m]
+				}
+			}
+		}
+	}
+
+	result := Strength{Bits: best[n]}
+	seen := make(map[string]bool)
+	for k := n; k > 0; {
+		if m := via[k]; m != nil {
+			if !seen[m.warning] {
+				seen[m.warning] = true
+				result.Warnings = append([]string{m.warning}, result.Warnings...)
+			}
+			k = m.i
+		} else {
+			k--
+		}
+	}
+	if n < 8 {
+		result.Warnings = append(result.Warnings, warnShort)
+	}
+
+	result.Score = ScoreVeryStrong
+	for i, bound := range scoreBits {
+		if result.Bits < bound {
+			result.Score = i
+			break
+		}
+	}
+	return result
+}
+
+// poolSize is the brute-force alphabet size for the character classes used in password.
+func poolSize(password string) int {
+	var lower, upper, digit, other, nonASCII bool
+	for _, r := range password {
+		switch {
+		case r > unicode.MaxASCII:
+			nonASCII = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+	size := 0
+	for _, c := range []struct {
+		used bool
+		size int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {other, 33}, {nonASCII, 100}} {
+		if c.used {
+			size += c.size
+		}
+	}
+	return size
+}
+
+// dictionaryMatches finds dictionary words, their l33t and reversed forms, and user inputs.
+func dictionaryMatches(password string, userInputs []string) []match {
+	dict := loadDictionary()
+	inputs := make(map[string]int)
+	for _, in := range userInputs {
+		for _, part := range strings.FieldsFunc(strings.ToLower(in), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if len(part) >= 3 {
+				inputs[part] = len(inputs) + 1
+			}
+		}
+	}
+	lookup := func(word string) (dictEntry, bool) {
+		if rank, ok := inputs[word]; ok {
+			return dictEntry{rank: rank, warning: warnUserInput}, true
+		}
+		e, ok := dict[word]
+		return e, ok
+	}
+
+	lower := strings.ToLower(password)
+	unleet := leetReplacer.Replace(lower)
+	var matches []match
+	for i := 0; i < len(password); i++ {
+		for j := i + 3; j <= len(password); j++ {
+			caseBits := upperCaseBits(password[i:j])
+			if e, ok := lookup(lower[i:j]); ok {
+				matches = append(matches, match{i, j, math.Log2(float64(e.rank)) + caseBits, e.warning})
+			}
+			// Replacements are single-byte, so unleet lines up with password
+			if len(unleet) == len(lower) && unleet[i:j] != lower[i:j] {
+				if e, ok := lookup(unleet[i:j]); ok {
+					matches = append(matches, match{i, j, math.Log2(float64(e.rank)) + caseBits + 1, e.warning})
+				}
+			}
+			if j-i >= 4 {
+				if e, ok := lookup(reverse(lower[i:j])); ok {
+					matches = append(matches, match{i, j, math.Log2(float64(e.rank)) + caseBits + 1, e.warning})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// upperCaseBits is the extra entropy of the capitalization of a dictionary word:
+// none for all lower case, one bit for Capitalized or ALL CAPS, more for mixed case.
+func upperCaseBits(word string) float64 {
+	var upper, lower int
+	for _, r := range word {
+		if unicode.IsUpper(r) {
+			upper++
+		} else if unicode.IsLower(r) {
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 0
+	}
+	if lower == 0 || (upper == 1 && unicode.IsUpper(rune(word[0]))) {
+		return 1
+	}
+	// Number of ways to place up to min(upper, lower) capitals
+	var ways float64
+	for k := 1; k <= upper && k <= lower; k++ {
+		ways += binomial(upper+lower, k)
+	}
+	return math.Log2(ways)
+}
+
+func binomial(n, k int) float64 {
+	r := 1.0
+	for i := 1; i <= k; i++ {
+		r = r * float64(n-k+i) / float64(i)
+	}
+	return r
+}
+
+func reverse(s string) string {
+	b := []byte(s)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return string(b)
+}
+
+// keyboardMatches finds runs of 3+ neighbouring keys such as "qwerty" or "zxcvb".
+func keyboardMatches(password string) []match {
+	var matches []match
+	for i := 0; i < len(password); {
+		j := i + 1
+		shifted := false
+		for j < len(password) {
+			a, shiftA, okA := keyPosition(password[j-1])
+			b, shiftB, okB := keyPosition(password[j])
+			if !okA || !okB || !adjacentKeys(a, b) {
+				break
+			}
+			shifted = shifted || shiftA || shiftB
+			j++
+		}
+		if j-i >= 3 {
+			// Start key, then about 2.2 bits for the direction of every further key
+			bits := math.Log2(47) + float64(j-i-1)*2.2
+			if shifted {
+				bits++
+			}
+			matches = append(matches, match{i, j, bits, warnKeyboard})
+			i = j
+			continue
+		}
+		i++
+	}
+	return matches
+}
+
+// sequenceMatches finds runs of 3+ consecutive letters or digits, ascending or descending (abc, 4321).
+func sequenceMatches(password string) []match {
+	var matches []match
+	for i := 0; i+2 < len(password); {
+		delta := int(password[i+1]) - int(password[i])
+		j := i + 1
+		if delta == 1 || delta == -1 {
+			for j < len(password) && int(password[j])-int(password[j-1]) == delta && sameClass(password[i], password[j]) {
+				j++
+			}
+		}
+		if j-i >= 3 {
+			base := math.Log2(26)
+			if password[i] >= '0' && password[i] <= '9' {
+				base = math.Log2(10)
+			}
+			if strings.IndexByte("aAzZ019", password[i]) >= 0 {
+				base = 1 // obvious starting points
+			}
+			bits := base + math.Log2(float64(j-i))
+			if delta < 0 {
+				bits++
+			}
+			matches = append(matches, match{i, j, bits, warnSequence})
+			i = j
+			continue
+		}
+		i++
+	}
+	return matches
+}
+
+func sameClass(a, b byte) bool {
+	class := func(c byte) int {
+		switch {
+		case c >= 'a' && c <= 'z':
+			return 1
+		case c >= 'A' && c <= 'Z':
+			return 2
+		case c >= '0' && c <= '9':
+			return 3
+		}
+		return 0
+	}
+	return class(a) != 0 && class(a) == class(b)
+}
+
+// repeatMatches finds repeated characters (aaaa) and repeated blocks (abcabc).
+func repeatMatches(password string) []match {
+	var matches []match
+	n := len(password)
+	for i := 0; i < n; i++ {
+		for size := 1; i+2*size <= n; size++ {
+			block := password[i : i+size]
+			j := i + size
+			for j+size <= n && password[j:j+size] == block {
+				j += size
+			}
+			count := (j - i) / size
+			if count < 2 || (size == 1 && count < 3) {
+				continue
+			}
+			blockBits := float64(size) * math.Log2(float64(poolSize(block)))
+			matches = append(matches, match{i, j, blockBits + math.Log2(float64(count)), warnRepeat})
+		}
+	}
+	return matches
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEstimateStrengthScores(t *testing.T) {
+	tests := []struct {
+		password   string
+		userInputs []string
+		minScore   int
+		maxScore   int
+	}{
+		{"", nil, ScoreVeryWeak, ScoreVeryWeak},
+		{"password", nil, ScoreVeryWeak, ScoreVeryWeak},
+		{"P@ssw0rd", nil, ScoreVeryWeak, ScoreVeryWeak},
+		{"drowssap", nil, ScoreVeryWeak, ScoreVeryWeak},
+		{"123456", nil, ScoreVeryWeak, ScoreVeryWeak},
+		{"qwertyuiop", nil, ScoreVeryWeak, ScoreVeryWeak},
+		{"abcdefgh", nil, ScoreVeryWeak, ScoreVeryWeak},
+		{"aaaaaaaaaa", nil, ScoreVeryWeak, ScoreVeryWeak},
+		{"abcabcabc", nil, ScoreVeryWeak, ScoreVeryWeak},
+		{"Summer2019", nil, ScoreVeryWeak, ScoreWeak},
+		{"github123", []string{"github"}, ScoreVeryWeak, ScoreVeryWeak},
+		{"Xy7#", nil, ScoreVeryWeak, ScoreWeak},
+		{"tr0ub4dour&3", nil, ScoreStrong, ScoreVeryStrong},
+		{"kT9#vQ2!mZ8@wL4$", nil, ScoreVeryStrong, ScoreVeryStrong},
+		{"correct horse battery staple", nil, ScoreVeryStrong, ScoreVeryStrong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			s := EstimateStrength(tt.password, tt.userInputs...)
+			if s.Score < tt.minScore || s.Score > tt.maxScore {
+				t.Errorf("EstimateStrength(%q) = %s (%.1f bits), want %s to %s",
+					tt.password, s.Label(), s.Bits, ScoreLabel(tt.minScore), ScoreLabel(tt.maxScore))
+			}
+		})
+	}
+}
+
+func TestEstimateStrengthWarnings(t *testing.T) {
+	tests := []struct {
+		password   string
+		userInputs []string
+		want       []string
+	}{
+		{"", nil, []string{"is empty"}},
+		{"Kp8#zxcvbn", nil, []string{warnCommon}},
+		{"Kp8#Qzvolcano", nil, []string{warnWord}},
+		{"Kp8#Qzv0lcan0", nil, []string{warnWord}},
+		{"Kp8#Qzmybank", []string{"mybank.com"}, []string{warnUserInput}},
+		{"#Kp8wsxcde", nil, []string{warnKeyboard}},
+		{"Kp8#Qzmnopqr", nil, []string{warnSequence}},
+		{"Kp8#Qz!!!!!!", nil, []string{warnRepeat}},
+		{"Kp8#Qz1987", nil, []string{warnYear}},
+		{"Kp8#Qz", nil, []string{warnShort}},
+		{"Summer2019", nil, []string{warnCommon, warnYear}},
+		{"123456", nil, []string{warnCommon, warnShort}},
+		{"kT9#vQ2!mZ8@wL4$", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			if got := EstimateStrength(tt.password, tt.userInputs...).Warnings; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("warnings of %q = %q, want %q", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimateStrengthOrdering(t *testing.T) {
+	// Each password is a harder variant of the previous one
+	passwords := []string{"monkey", "Monkey", "Monkey7", "Monkey7#qz", "Monkey7#qzLw2!"}
+	prev := -1.0
+	for _, pw := range passwords {
+		bits := EstimateStrength(pw).Bits
+		if bits <= prev {
+			t.Errorf("EstimateStrength(%q) = %.1f bits, want more than %.1f", pw, bits, prev)
+		}
+		prev = bits
+	}
+}
+
+func TestScoreLabel(t *testing.T) {
+	tests := []struct {
+		score int
+		want  string
+	}{
+		{ScoreVeryWeak, "very weak"},
+		{ScoreFair, "fair"},
+		{ScoreVeryStrong, "very strong"},
+		{-1, "unknown"},
+		{ScoreVeryStrong + 1, "unknown"},
+	}
+	for _, tt := range tests {
+		if got := ScoreLabel(tt.score); got != tt.want {
+			t.Errorf("ScoreLabel(%d) = %q, want %q", tt.score, got, tt.want)
+		}
+	}
+}
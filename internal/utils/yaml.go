@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// yamlPlainKey matches mapping keys that need no quoting.
+var yamlPlainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// yamlNode is a decoded JSON value that keeps the order of object keys.
+type yamlNode struct {
+	keys   []string    // object keys, in order
+	values []*yamlNode // object values or array items
+	array  bool
+	scalar string // JSON text of a string, number, bool or null
+}
+
+// JSONToYAML converts a JSON document to block-style YAML, keeping the order of object keys.
+// Strings are written double-quoted, which YAML reads exactly like JSON.
+func JSONToYAML(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	root, err := decodeYAMLNode(dec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to YAML: %w", err)
+	}
+	var b strings.Builder
+	if root.isCollection() && len(root.values) > 0 {
+		writeYAMLNode(&b, root, 0)
+	} else {
+		b.WriteString(root.inline() + "\n")
+	}
+	return []byte(b.String()), nil
+}
+
+func decodeYAMLNode(dec *json.Decoder) (*yamlNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		n := &yamlNode{array: t == '['}
+		for dec.More() {
+			if !n.array {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				n.keys = append(n.keys, keyTok.(string))
+			}
+			v, err := decodeYAMLNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.values = append(n.values, v)
+		}
+		if _, err := dec.Token(); err != nil { // closing delimiter
+			return nil, err
+		}
+		return n, nil
+	case string:
+		s, _ := json.Marshal(t)
+		return &yamlNode{scalar: string(s)}, nil
+	case nil:
+		return &yamlNode{scalar: "null"}, nil
+	default:
+		return &yamlNode{scalar: fmt.Sprint(t)}, nil
+	}
+}
+
+func (n *yamlNode) isCollection() bool {
+	return n.scalar == ""
+}
+
+// inline returns scalars and empty collections in flow style.
+func (n *yamlNode) inline() string {
+	switch {
+	case !n.isCollection():
+		return n.scalar
+	case n.array:
+		return "[]"
+	}
+	return "{}"
+}
+
+// writeYAMLNode writes a non-empty collection, each line indented by indent spaces.
+func writeYAMLNode(b *strings.Builder, n *yamlNode, indent int) {
+	pad := strings.Repeat(" ", indent)
+	for i, v := range n.values {
+		if n.array {
+			b.WriteString(pad + "-")
+		} else {
+			b.WriteString(pad + yamlKey(n.keys[i]) + ":")
+		}
+		if !v.isCollection() || len(v.values) == 0 {
+			b.WriteString(" " + v.inline() + "\n")
+			continue
+		}
+		if n.array && !v.array {
+			// "- key: value" with the following keys aligned under the first
+			var sub strings.Builder
+			writeYAMLNode(&sub, v, indent+2)
+			b.WriteString(" " + strings.TrimPrefix(sub.String(), pad+"  "))
+			continue
+		}
+		b.WriteString("\n")
+		writeYAMLNode(b, v, indent+2)
+	}
+}
+
+func yamlKey(k string) string {
+	if yamlPlainKey.MatchString(k) && !isYAMLKeyword(k) {
+		return k
+	}
+	s, _ := json.Marshal(k)
+	return string(s)
+}
+
+// isYAMLKeyword reports whether a plain key would read as a boolean or null.
+func isYAMLKeyword(k string) bool {
+	switch strings.ToLower(k) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null":
+		return true
+	}
+	return false
+}
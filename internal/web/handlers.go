@@ -2,25 +2,46 @@ package web
 
 import (
 	"encoding/json"
+	"errors"
+	"html/template"
+	"math"
 	"net/http"
+	"net/url"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"go-passman/internal/audit"
 	"go-passman/internal/models"
+	"go-passman/internal/otp"
+	"go-passman/internal/search"
 	"go-passman/internal/storage"
+	"go-passman/internal/utils"
 )
 
 const listPerPage = 20
 
 type listData struct {
-	Entries      []listEntry
-	Total        int   // total in vault
-	TotalFiltered int   // after search
-	Query        string
-	Page         int
-	TotalPages   int
-	PerPage      int
+	Entries       []listEntry
+	Total         int // total in vault
+	TotalFiltered int // after search
+	Query         string
+	Tag           string       // current tag filter
+	Folder        string       // current folder filter
+	Filters       template.URL // "&tag=..&folder=.." for links that keep the filters
+	Tags          []facet
+	Folders       []facet
+	Version       string // for the bulk tag form
+	Back          string // this page, where the bulk tag form returns to
+	Sort          string // "" (by name) or one of models.TimeFields, newest first
+	Expired       int    // passwords past their rotation/expiry date
+	DueSoon       int    // passwords due within audit.DefaultDueWithin
+	DueDays       int
+	Page          int
+	TotalPages    int
+	PerPage       int
 }
 
 type listEntry struct {
@@ -29,6 +50,58 @@ type listEntry struct {
 	Login   string
 	Host    string
 	Comment string
+	Reused  bool // password shared with another entry (see audit.Reuse)
+	Folder  string
+	Tags    []string
+
+	Created, Modified, Changed, Used time.Time
+}
+
+// facet is a tag or folder link in the list sidebar; URL selects it (or clears it when Active).
+type facet struct {
+	Name   string
+	Count  int
+	URL    template.URL
+	Active bool
+}
+
+// listURL returns the list page URL for a search, sort and tag/folder filter.
+func listURL(query, sortBy, tag, folder string) template.URL {
+	vals := url.Values{}
+	for k, v := range map[string]string{"q": query, "sort": sortBy, "tag": tag, "folder": folder} {
+		if v != "" {
+			vals.Set(k, v)
+		}
+	}
+	return template.URL("/?" + vals.Encode())
+}
+
+// facets turns tag or folder counts into sorted sidebar links; link builds the URL selecting a name.
+func facets(counts map[string]int, current string, link func(name string) template.URL) []facet {
+	list := make([]facet, 0, len(counts))
+	for name, n := range counts {
+		f := facet{Name: name, Count: n, URL: link(name)}
+		if strings.EqualFold(name, current) {
+			f.Active = true
+			f.URL = link("")
+		}
+		list = append(list, f)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// historyItem is a previous password on the edit page (the password itself is only sent by /api/copy).
+type historyItem struct {
+	Num       int
+	ChangedAt string
+}
+
+// formData is passed to the add and delete templates. Version is the vault fingerprint the form was rendered from.
+type formData struct {
+	Error   string
+	Name    string
+	Version string
 }
 
 func listHandler(w http.ResponseWriter, r *http.Request) {
@@ -36,7 +109,7 @@ func listHandler(w http.ResponseWriter, r *http.Request) {
 		http.NotFound(w, r)
 		return
 	}
-	v, _, ok := loadVault(w, r)
+	v, _, version, ok := loadVault(w, r)
 	if !ok {
 		return
 	}
@@ -45,6 +118,7 @@ func listHandler(w http.ResponseWriter, r *http.Request) {
 		names = append(names, n)
 	}
 	sort.Strings(names)
+	reused := audit.Reused(v.Entries)
 	all := make([]listEntry, 0, len(names))
 	for i, name := range names {
 		e := v.Entries[name]
@@ -54,22 +128,34 @@ func listHandler(w http.ResponseWriter, r *http.Request) {
 			Login:   e.Login,
 			Host:    e.Host,
 			Comment: e.Comment,
+			Reused:  reused[name],
+			Folder:  e.Folder,
+			Tags:    e.Tags,
+
+			Created:  e.CreatedAt,
+			Modified: e.ModifiedAt,
+			Changed:  e.PasswordChangedAt,
+			Used:     e.AccessedAt,
 		})
 	}
 
 	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	tag := models.NormalizeTag(r.URL.Query().Get("tag"))
+	folder := models.CleanFolder(r.URL.Query().Get("folder"))
+	filtering := query != "" || tag != "" || folder != ""
 	filtered := all
-	if query != "" {
-		q := strings.ToLower(query)
+	scores := make(map[string]int)
+	if filtering {
+		q := search.Parse(query)
 		filtered = make([]listEntry, 0)
 		for i, e := range all {
-			if strings.Contains(strings.ToLower(e.Name), q) ||
-				strings.Contains(strings.ToLower(e.Login), q) ||
-				strings.Contains(strings.ToLower(e.Host), q) ||
-				strings.Contains(strings.ToLower(e.Comment), q) {
-				filtered = append(filtered, listEntry{
-					Num: i + 1, Name: e.Name, Login: e.Login, Host: e.Host, Comment: e.Comment,
-				})
+			entry := v.Entries[e.Name]
+			if (tag == "" || entry.HasTag(tag)) && entry.InFolder(folder) {
+				if score, ok := q.Score(e.Name, entry); ok {
+					scores[e.Name] = score
+					e.Num = i + 1
+					filtered = append(filtered, e)
+				}
 			}
 		}
 		// renumber filtered list 1..n
@@ -78,6 +164,33 @@ func listHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	sortBy := r.URL.Query().Get("sort")
+	if _, ok := (models.PasswordEntry{}).Time(sortBy); ok {
+		if !filtering {
+			filtered = append([]listEntry(nil), all...)
+		}
+		// Newest first; unknown (zero) times last
+		sort.SliceStable(filtered, func(i, j int) bool {
+			ti, _ := v.Entries[filtered[i].Name].Time(sortBy)
+			tj, _ := v.Entries[filtered[j].Name].Time(sortBy)
+			return ti.After(tj)
+		})
+		for i := range filtered {
+			filtered[i].Num = i + 1
+		}
+	} else {
+		sortBy = ""
+		if query != "" {
+			// Best matches first, by name on equal scores
+			sort.SliceStable(filtered, func(i, j int) bool {
+				return scores[filtered[i].Name] > scores[filtered[j].Name]
+			})
+			for i := range filtered {
+				filtered[i].Num = i + 1
+			}
+		}
+	}
+
 	totalFiltered := len(filtered)
 	totalPages := 1
 	if totalFiltered > listPerPage {
@@ -102,23 +215,39 @@ func listHandler(w http.ResponseWriter, r *http.Request) {
 		pageEntries[i].Num = start + i + 1
 	}
 
+	var filters template.URL
+	if f := string(listURL("", "", tag, folder)); f != "/?" {
+		filters = template.URL("&" + strings.TrimPrefix(f, "/?"))
+	}
+	expired, dueSoon := audit.CountDue(audit.Due(v.Entries, time.Now(), audit.DefaultDueWithin))
 	tmpl.ExecuteTemplate(w, "list.html", listData{
 		Entries:       pageEntries,
 		Total:         len(all),
 		TotalFiltered: totalFiltered,
 		Query:         query,
-		Page:         page,
-		TotalPages:   totalPages,
-		PerPage:      listPerPage,
+		Tag:           tag,
+		Folder:        folder,
+		Filters:       filters,
+		Tags: facets(v.TagCounts(), tag, func(t string) template.URL {
+			return listURL(query, sortBy, t, folder)
+		}),
+		Folders: facets(v.FolderCounts(), folder, func(f string) template.URL {
+			return listURL(query, sortBy, tag, f)
+		}),
+		Version:    version,
+		Back:       r.URL.RequestURI(),
+		Sort:       sortBy,
+		Expired:    expired,
+		DueSoon:    dueSoon,
+		DueDays:    int(audit.DefaultDueWithin.Hours() / 24),
+		Page:       page,
+		TotalPages: totalPages,
+		PerPage:    listPerPage,
 	})
 }
 
 func logoutHandler(w http.ResponseWriter, r *http.Request) {
-	vaultMu.Lock()
-	vault = nil
-	vaultPwd = nil
-	vaultPwdStored = ""
-	vaultMu.Unlock()
+	lockSession()
 	http.Redirect(w, r, "/unlock", http.StatusFound)
 }
 
@@ -137,6 +266,11 @@ func unlockHandler(w http.ResponseWriter, r *http.Request) {
 			tmpl.ExecuteTemplate(w, "unlock.html", "Password required")
 			return
 		}
+		version, err := storage.Fingerprint()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
 		v, _, err := storage.LoadVaultWithPassword(&pwd)
 		if err != nil {
 			tmpl.ExecuteTemplate(w, "unlock.html", "Wrong password or error: "+err.Error())
@@ -146,6 +280,7 @@ func unlockHandler(w http.ResponseWriter, r *http.Request) {
 		vault = v
 		vaultPwdStored = pwd
 		vaultPwd = &vaultPwdStored
+		vaultVersion = version
 		vaultMu.Unlock()
 		http.Redirect(w, r, "/", http.StatusFound)
 		return
@@ -153,8 +288,224 @@ func unlockHandler(w http.ResponseWriter, r *http.Request) {
 	tmpl.ExecuteTemplate(w, "unlock.html", nil)
 }
 
+// errConflict means vault.json changed on disk after the form was rendered.
+var errConflict = errors.New("vault was changed outside the web UI")
+
+// saveChange applies change to the cached vault and saves it, holding the cross-process vault lock.
+// If vault.json no longer matches version (the fingerprint the form was rendered from), nothing is
+// changed and errConflict is returned. Errors returned by change are passed through unchanged.
+func saveChange(version string, change func(v *models.Vault) error) error {
+	return applyChange(version, false, change)
+}
+
+// recordAccess saves the last-used time of name.
+// It is best effort: a conflict or busy vault only means the time is not recorded.
+func recordAccess(version, name string) {
+	applyChange(version, true, func(v *models.Vault) error {
+		entry, exists := v.Entries[name]
+		if !exists {
+			return nil
+		}
+		entry.AccessedAt = time.Now()
+		v.Entries[name] = entry
+		return nil
+	})
+}
+
+// accessParents maps a fingerprint written by recordAccess to the one it replaced, so forms rendered
+// before a copy or reveal still save: only last-used times changed in between.
+var accessParents = map[string]string{}
+
+// sameContent reports whether version is current or only differs from it by recordAccess saves.
+func sameContent(version, current string) bool {
+	for v := current; v != ""; v = accessParents[v] {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// saveMu serializes saves of the web UI: the vault file lock is held per process (see storage.WithLock).
+var saveMu sync.Mutex
+
+// applyChange runs change on a copy of the cached vault, saves it and then makes the copy the cached
+// vault, as described for saveChange. Handlers keep reading the vault they got from loadVault, so the
+// cached vault is never changed in place. The file lock is taken before vaultMu, which is only held to
+// read and replace the cache: requests do not wait while another process holds the vault.
+// Access-only changes are written with storage.SaveVaultAccess (no backup rotation) and fail at once
+// with storage.ErrVaultBusy instead of waiting for the lock.
+func applyChange(version string, access bool, change func(v *models.Vault) error) error {
+	saveMu.Lock()
+	defer saveMu.Unlock()
+	withLock := storage.WithLock
+	if access {
+		withLock = storage.TryWithLock
+	}
+	return withLock(func() error {
+		current, err := storage.Fingerprint()
+		if err != nil {
+			return err
+		}
+		vaultMu.RLock()
+		cached, cachedVersion := vault, vaultVersion
+		var pwd *string
+		if vaultPwd != nil {
+			p := *vaultPwd
+			pwd = &p
+		}
+		vaultMu.RUnlock()
+		if cached == nil || current != cachedVersion || !sameContent(version, current) {
+			return errConflict
+		}
+		next := cached.Clone()
+		if err := change(next); err != nil {
+			return err
+		}
+		save := storage.SaveVault
+		if access {
+			save = storage.SaveVaultAccess
+		}
+		saveErr := save(next, pwd)
+		saved, err := storage.Fingerprint()
+		if saveErr != nil || err != nil {
+			saved = ""
+		}
+		vaultMu.Lock()
+		if vault == cached { // not locked or reloaded meanwhile
+			if saveErr == nil {
+				vault = next
+			}
+			// An empty version makes the next request reload the file
+			vaultVersion = saved
+		}
+		vaultMu.Unlock()
+		if saveErr != nil {
+			return saveErr
+		}
+		if access && saved != "" {
+			accessParents[saved] = current
+		} else {
+			accessParents = map[string]string{}
+		}
+		return nil
+	})
+}
+
+// saveFailed renders the response for an error returned by saveChange.
+func saveFailed(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, errConflict):
+		w.WriteHeader(http.StatusConflict)
+		tmpl.ExecuteTemplate(w, "conflict.html", r.URL.RequestURI())
+	case errors.Is(err, errInvalidRotation), errors.Is(err, errInvalidOTP):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, storage.ErrVaultBusy):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// errServiceExists is returned by the add change when the name is already taken.
+var errServiceExists = errors.New("Service already exists")
+
+// errInvalidRotation is returned for a bad rotation interval or expiry date in the add/edit forms.
+var errInvalidRotation = errors.New("Rotation must be a number of days and expiry a date (YYYY-MM-DD)")
+
+// errInvalidOTP is returned for a one-time password secret that cannot be parsed.
+var errInvalidOTP = errors.New("One-time password must be an otpauth:// URI or a base32 secret")
+
+// readOTP sets the entry's one-time password secret from the form ("otp"; empty keeps it, "remove_otp" clears it).
+func readOTP(r *http.Request, entry *models.PasswordEntry) error {
+	if r.FormValue("remove_otp") != "" {
+		entry.OTP = nil
+	}
+	if s := strings.TrimSpace(r.FormValue("otp")); s != "" {
+		key, err := otp.Parse(s)
+		if err != nil {
+			return errInvalidOTP
+		}
+		entry.OTP = &key
+	}
+	return nil
+}
+
+// readRotation sets the rotation interval ("rotate", days) and expiry date ("expires") from the form.
+func readRotation(r *http.Request, entry *models.PasswordEntry) error {
+	days := 0
+	if s := strings.TrimSpace(r.FormValue("rotate")); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return errInvalidRotation
+		}
+		days = n
+	}
+	var expires time.Time
+	if s := strings.TrimSpace(r.FormValue("expires")); s != "" {
+		t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+		if err != nil {
+			return errInvalidRotation
+		}
+		expires = t
+	}
+	entry.RotationDays = days
+	entry.ExpiresAt = expires
+	return nil
+}
+
+// readFields returns the custom fields posted by the add/edit form (field_name, field_value and
+// field_type, one per row). Concealed values are not sent to the edit form, so an empty concealed
+// value keeps the value from old.
+func readFields(r *http.Request, old []models.CustomField) []models.CustomField {
+	names, values, types := r.Form["field_name"], r.Form["field_value"], r.Form["field_type"]
+	prev := models.PasswordEntry{Fields: old}
+	var fields []models.CustomField
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || i >= len(values) || i >= len(types) {
+			continue
+		}
+		f := models.CustomField{Name: name, Value: values[i], Concealed: types[i] == "concealed"}
+		if f.Concealed && f.Value == "" {
+			if p := prev.Field(name); p != nil {
+				f.Value = p.Value
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// fieldInputs prepares custom fields for the edit form, leaving concealed values out of the page.
+func fieldInputs(fields []models.CustomField) []models.CustomField {
+	out := make([]models.CustomField, len(fields))
+	for i, f := range fields {
+		out[i] = f
+		if f.Concealed {
+			out[i].Value = ""
+		}
+	}
+	return out
+}
+
+// readOrganize sets the entry's tags (comma-separated) and folder from the add/edit form.
+func readOrganize(r *http.Request, entry *models.PasswordEntry) {
+	entry.Tags = nil
+	entry.AddTags(strings.Split(r.FormValue("tags"), ",")...)
+	entry.Folder = models.CleanFolder(r.FormValue("folder"))
+}
+
+// dateInput formats t for an <input type="date">, empty when unset.
+func dateInput(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02")
+}
+
 func addHandler(w http.ResponseWriter, r *http.Request) {
-	v, pwd, ok := loadVault(w, r)
+	_, _, version, ok := loadVault(w, r)
 	if !ok {
 		return
 	}
@@ -162,32 +513,48 @@ func addHandler(w http.ResponseWriter, r *http.Request) {
 		r.ParseForm()
 		name := strings.TrimSpace(r.FormValue("name"))
 		if name == "" {
-			tmpl.ExecuteTemplate(w, "add.html", "Service name is required")
+			tmpl.ExecuteTemplate(w, "add.html", formData{Error: "Service name is required", Version: version})
 			return
 		}
-		if _, exists := v.Entries[name]; exists {
-			tmpl.ExecuteTemplate(w, "add.html", "Service already exists")
+		err := saveChange(r.FormValue("version"), func(v *models.Vault) error {
+			if _, exists := v.Entries[name]; exists {
+				return errServiceExists
+			}
+			entry := models.PasswordEntry{
+				Login:   strings.TrimSpace(r.FormValue("login")),
+				Host:    strings.TrimSpace(r.FormValue("host")),
+				Comment: strings.TrimSpace(r.FormValue("comment")),
+			}
+			if err := readRotation(r, &entry); err != nil {
+				return err
+			}
+			entry.Fields = readFields(r, nil)
+			readOrganize(r, &entry)
+			if err := readOTP(r, &entry); err != nil {
+				return err
+			}
+			now := time.Now()
+			entry.SetPassword(r.FormValue("password"), now)
+			entry.Touch(now)
+			v.Entries[name] = entry
+			return nil
+		})
+		if errors.Is(err, errServiceExists) || errors.Is(err, errInvalidRotation) || errors.Is(err, errInvalidOTP) {
+			tmpl.ExecuteTemplate(w, "add.html", formData{Error: err.Error(), Version: version})
 			return
 		}
-		v.Entries[name] = models.PasswordEntry{
-			Login:     strings.TrimSpace(r.FormValue("login")),
-			Host:      strings.TrimSpace(r.FormValue("host")),
-			Comment:   strings.TrimSpace(r.FormValue("comment")),
-			Password:  r.FormValue("password"),
-			Encrypted: false,
-		}
-		if err := storage.SaveVault(v, pwd); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			saveFailed(w, r, err)
 			return
 		}
 		http.Redirect(w, r, "/", http.StatusFound)
 		return
 	}
-	tmpl.ExecuteTemplate(w, "add.html", nil)
+	tmpl.ExecuteTemplate(w, "add.html", formData{Version: version})
 }
 
 func editHandler(w http.ResponseWriter, r *http.Request) {
-	v, pwd, ok := loadVault(w, r)
+	v, _, version, ok := loadVault(w, r)
 	if !ok {
 		return
 	}
@@ -201,41 +568,139 @@ func editHandler(w http.ResponseWriter, r *http.Request) {
 		http.NotFound(w, r)
 		return
 	}
+	if r.Method == http.MethodPost && r.FormValue("restore") != "" {
+		restoreFromHistory(w, r, name)
+		return
+	}
 	if r.Method == http.MethodPost {
 		r.ParseForm()
 		newName := strings.TrimSpace(r.FormValue("name"))
 		if newName == "" {
 			newName = name
 		}
-		entry.Login = strings.TrimSpace(r.FormValue("login"))
-		entry.Host = strings.TrimSpace(r.FormValue("host"))
-		entry.Comment = strings.TrimSpace(r.FormValue("comment"))
-		if p := r.FormValue("password"); p != "" {
-			entry.Password = p
-		}
-		if newName != name {
-			delete(v.Entries, name)
-		}
-		v.Entries[newName] = entry
-		if err := storage.SaveVault(v, pwd); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		err := saveChange(r.FormValue("version"), func(v *models.Vault) error {
+			entry := v.Entries[name]
+			entry.Login = strings.TrimSpace(r.FormValue("login"))
+			entry.Host = strings.TrimSpace(r.FormValue("host"))
+			entry.Comment = strings.TrimSpace(r.FormValue("comment"))
+			if err := readRotation(r, &entry); err != nil {
+				return err
+			}
+			entry.Fields = readFields(r, entry.Fields)
+			readOrganize(r, &entry)
+			if err := readOTP(r, &entry); err != nil {
+				return err
+			}
+			now := time.Now()
+			if p := r.FormValue("password"); p != "" {
+				entry.SetPassword(p, now)
+			}
+			entry.Touch(now)
+			if newName != name {
+				delete(v.Entries, name)
+			}
+			v.Entries[newName] = entry
+			return nil
+		})
+		if err != nil {
+			saveFailed(w, r, err)
 			return
 		}
 		http.Redirect(w, r, "/", http.StatusFound)
 		return
 	}
+	history := make([]historyItem, len(entry.History))
+	for i, h := range entry.History {
+		history[i] = historyItem{Num: i + 1, ChangedAt: h.ChangedAt.Local().Format("2006-01-02 15:04")}
+	}
 	data := map[string]interface{}{
 		"Name":    name,
 		"Login":   entry.Login,
 		"Host":    entry.Host,
 		"Comment": entry.Comment,
+		"History": history,
+		"Fields":  fieldInputs(entry.Fields),
+		"Tags":    strings.Join(entry.Tags, ", "),
+		"Folder":  entry.Folder,
+		"OTP":     otpLabel(entry),
+		"Rotate":  entry.RotationDays,
+		"Expires": dateInput(entry.ExpiresAt),
+		"Version": version,
 		"Error":   nil,
 	}
 	tmpl.ExecuteTemplate(w, "edit.html", data)
 }
 
+// restoreFromHistory makes history item restore=N the current password of name (POST to /edit).
+func restoreFromHistory(w http.ResponseWriter, r *http.Request, name string) {
+	n, err := strconv.Atoi(r.FormValue("restore"))
+	if err != nil {
+		http.Error(w, "invalid history item", http.StatusBadRequest)
+		return
+	}
+	err = saveChange(r.FormValue("version"), func(v *models.Vault) error {
+		entry := v.Entries[name]
+		now := time.Now()
+		if err := entry.RestorePassword(n-1, now); err != nil {
+			return err
+		}
+		entry.Touch(now)
+		v.Entries[name] = entry
+		return nil
+	})
+	if err != nil {
+		saveFailed(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/edit?name="+url.QueryEscape(name), http.StatusFound)
+}
+
+// tagsHandler adds or removes tags on the entries checked in the list (POST from the bulk form).
+func tagsHandler(w http.ResponseWriter, r *http.Request) {
+	if _, _, _, ok := loadVault(w, r); !ok {
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+	r.ParseForm()
+	back := r.FormValue("back")
+	if !strings.HasPrefix(back, "/") || strings.HasPrefix(back, "//") {
+		back = "/"
+	}
+	names := r.Form["name"]
+	tags := strings.Split(r.FormValue("tags"), ",")
+	remove := r.FormValue("action") == "remove"
+	err := saveChange(r.FormValue("version"), func(v *models.Vault) error {
+		now := time.Now()
+		for _, name := range names {
+			entry, ok := v.Entries[name]
+			if !ok {
+				continue
+			}
+			changed := false
+			if remove {
+				changed = entry.RemoveTags(tags...)
+			} else {
+				changed = entry.AddTags(tags...)
+			}
+			if changed {
+				entry.Touch(now)
+				v.Entries[name] = entry
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		saveFailed(w, r, err)
+		return
+	}
+	http.Redirect(w, r, back, http.StatusFound)
+}
+
 func deleteHandler(w http.ResponseWriter, r *http.Request) {
-	v, pwd, ok := loadVault(w, r)
+	_, _, version, ok := loadVault(w, r)
 	if !ok {
 		return
 	}
@@ -245,19 +710,23 @@ func deleteHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if r.Method == http.MethodPost {
-		delete(v.Entries, name)
-		if err := storage.SaveVault(v, pwd); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		r.ParseForm()
+		err := saveChange(r.FormValue("version"), func(v *models.Vault) error {
+			delete(v.Entries, name)
+			return nil
+		})
+		if err != nil {
+			saveFailed(w, r, err)
 			return
 		}
 		http.Redirect(w, r, "/", http.StatusFound)
 		return
 	}
-	tmpl.ExecuteTemplate(w, "delete.html", name)
+	tmpl.ExecuteTemplate(w, "delete.html", formData{Name: name, Version: version})
 }
 
 func copyHandler(w http.ResponseWriter, r *http.Request) {
-	v, _, ok := loadVault(w, r)
+	v, _, version, ok := loadVault(w, r)
 	if !ok {
 		return
 	}
@@ -271,12 +740,23 @@ func copyHandler(w http.ResponseWriter, r *http.Request) {
 		http.NotFound(w, r)
 		return
 	}
+	password := entry.Password
+	// history=N copies the Nth previous password
+	if h := r.URL.Query().Get("history"); h != "" {
+		n, err := strconv.Atoi(h)
+		if err != nil || n < 1 || n > len(entry.History) {
+			http.NotFound(w, r)
+			return
+		}
+		password = entry.History[n-1].Password
+	}
+	recordAccess(version, name)
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"password": entry.Password})
+	json.NewEncoder(w).Encode(map[string]string{"password": password})
 }
 
 func showHandler(w http.ResponseWriter, r *http.Request) {
-	v, _, ok := loadVault(w, r)
+	v, _, version, ok := loadVault(w, r)
 	if !ok {
 		return
 	}
@@ -290,5 +770,138 @@ func showHandler(w http.ResponseWriter, r *http.Request) {
 		http.NotFound(w, r)
 		return
 	}
-	tmpl.ExecuteTemplate(w, "show.html", map[string]string{"Name": name, "Password": entry.Password})
+	recordAccess(version, name)
+	tmpl.ExecuteTemplate(w, "show.html", map[string]interface{}{
+		"Name":     name,
+		"Password": entry.Password,
+		"Fields":   entry.Fields,
+		"TOTP":     entry.OTP != nil && entry.OTP.Type == otp.TypeTOTP,
+	})
+}
+
+// otpLabel describes the entry's one-time password secret, or "" when it has none.
+func otpLabel(entry models.PasswordEntry) string {
+	if entry.OTP == nil {
+		return ""
+	}
+	return otp.Label(*entry.OTP)
+}
+
+// otpHandler returns the current TOTP code of an entry and the seconds it stays valid (polled by the show page).
+func otpHandler(w http.ResponseWriter, r *http.Request) {
+	v, _, _, ok := loadVault(w, r)
+	if !ok {
+		return
+	}
+	entry, exists := v.Entries[r.URL.Query().Get("name")]
+	if !exists || entry.OTP == nil || entry.OTP.Type != otp.TypeTOTP {
+		http.NotFound(w, r)
+		return
+	}
+	code, remaining, err := otp.TOTP(*entry.OTP, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":      code,
+		"remaining": int(remaining.Seconds()),
+		"period":    entry.OTP.Period,
+	})
+}
+
+// auditData is passed to the audit template.
+type auditData struct {
+	Total    int
+	MinScore int
+	MinLabel string
+	Weak     []auditEntry
+	Reuse    []audit.ReuseGroup
+	Due      []dueItem
+	DueDays  int
+}
+
+// dueItem is an expired or soon-due password on the audit page.
+type dueItem struct {
+	Name    string
+	Date    string // "" when the last password change is unknown
+	Expired bool
+}
+
+type auditEntry struct {
+	Name     string
+	Label    string
+	Score    int
+	Bits     int
+	Warnings []string
+}
+
+// auditHandler lists entries with weak passwords (never the passwords). Query: min=1..4.
+func auditHandler(w http.ResponseWriter, r *http.Request) {
+	v, _, _, ok := loadVault(w, r)
+	if !ok {
+		return
+	}
+	minScore := audit.DefaultMinScore
+	if n, err := strconv.Atoi(r.URL.Query().Get("min")); err == nil && n >= utils.ScoreWeak && n <= utils.ScoreVeryStrong {
+		minScore = n
+	}
+	data := auditData{Total: len(v.Entries), MinScore: minScore, MinLabel: utils.ScoreLabel(minScore)}
+	for _, weak := range audit.Weak(v.Entries, minScore) {
+		data.Weak = append(data.Weak, auditEntry{
+			Name:     weak.Service,
+			Label:    weak.Strength.Label(),
+			Score:    weak.Strength.Score,
+			Bits:     int(math.Round(weak.Strength.Bits)),
+			Warnings: weak.Strength.Warnings,
+		})
+	}
+	data.Reuse = audit.Reuse(v.Entries)
+	data.DueDays = int(audit.DefaultDueWithin.Hours() / 24)
+	for _, d := range audit.Due(v.Entries, time.Now(), audit.DefaultDueWithin) {
+		data.Due = append(data.Due, dueItem{Name: d.Service, Date: dateInput(d.DueAt), Expired: d.Expired})
+	}
+	tmpl.ExecuteTemplate(w, "audit.html", data)
+}
+
+// generateHandler returns a generated password or passphrase with its entropy (used by the add form).
+// Query: mode=passphrase&words=6&sep=-&cap=1&digit=1, or mode=password&length=16&numbers=1&special=1&ambiguous=0.
+func generateHandler(w http.ResponseWriter, r *http.Request) {
+	if _, _, _, ok := loadVault(w, r); !ok {
+		return
+	}
+	q := r.URL.Query()
+	var password string
+	var entropy float64
+	var err error
+	if q.Get("mode") == "passphrase" {
+		p := utils.DefaultPassphrasePolicy()
+		if n, convErr := strconv.Atoi(q.Get("words")); convErr == nil {
+			p.Words = n
+		}
+		if q.Has("sep") {
+			p.Separator = q.Get("sep")
+		}
+		p.Capitalize = q.Get("cap") == "1"
+		p.Digit = q.Get("digit") == "1"
+		password, err = utils.GeneratePassphrase(p)
+		entropy = p.Entropy()
+	} else {
+		p := utils.DefaultPasswordPolicy()
+		if n, convErr := strconv.Atoi(q.Get("length")); convErr == nil {
+			p.Length = n
+		}
+		p.Numbers = q.Get("numbers") != "0"
+		p.Special = q.Get("special") != "0"
+		p.ExcludeAmbiguous = q.Get("ambiguous") == "0"
+		password, err = utils.GeneratePassword(p)
+		entropy = p.Entropy()
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"password": password, "entropy": math.Round(entropy)})
 }
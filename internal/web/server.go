@@ -9,6 +9,7 @@ import (
 	"os"
 	"strconv"
 	"sync"
+	"time"
 
 	"go-passman/internal/models"
 	"go-passman/internal/storage"
@@ -18,11 +19,12 @@ import (
 var templatesFS embed.FS
 
 var (
-	tmpl       *template.Template
-	vault      *models.Vault
-	vaultPwd   *string
+	tmpl           *template.Template
+	vault          *models.Vault
+	vaultPwd       *string
 	vaultPwdStored string // keep password on heap so vaultPwd stays valid
-	vaultMu    sync.RWMutex
+	vaultVersion   string // storage.Fingerprint of the file the cached vault was loaded from
+	vaultMu        sync.RWMutex
 )
 
 func init() {
@@ -30,7 +32,20 @@ func init() {
 		"urlquery": url.QueryEscape,
 		"add":      func(a, b int) int { return a + b },
 		"sub":      func(a, b int) int { return a - b },
-		"iterate":  func(start, end int) (out []int) {
+		// sortFields are the sortable timestamp columns of the list (keys from models.TimeFields)
+		"sortFields": func() []struct{ Key, Label string } {
+			return []struct{ Key, Label string }{
+				{"created", "Created"}, {"modified", "Modified"}, {"changed", "Pw changed"}, {"used", "Last used"},
+			}
+		},
+		// date shows a timestamp as YYYY-MM-DD, or "—" when unknown
+		"date": func(t time.Time) string {
+			if t.IsZero() {
+				return "—"
+			}
+			return t.Local().Format("2006-01-02")
+		},
+		"iterate": func(start, end int) (out []int) {
 			for i := start; i <= end; i++ {
 				out = append(out, i)
 			}
@@ -48,31 +63,60 @@ func init() {
 }
 
 // loadVault loads vault into memory (with optional password for encrypted vault).
-func loadVault(w http.ResponseWriter, r *http.Request) (*models.Vault, *string, bool) {
-	vaultMu.RLock()
-	v, p := vault, vaultPwd
-	vaultMu.RUnlock()
-	if v != nil {
-		return v, p, true
-	}
-	enc, err := storage.IsVaultEncrypted()
+// The cached vault is reloaded when vault.json was changed on disk (e.g. by the CLI) since it was loaded.
+// The returned version identifies the vault file the caller sees; forms send it back so saves can detect conflicts.
+// The returned vault is shared with other requests and must not be changed: use saveChange, which replaces it.
+func loadVault(w http.ResponseWriter, r *http.Request) (*models.Vault, *string, string, bool) {
+	current, err := storage.Fingerprint()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return nil, nil, false
+		return nil, nil, "", false
 	}
-	if enc {
-		http.Redirect(w, r, "/unlock", http.StatusFound)
-		return nil, nil, false
+	vaultMu.RLock()
+	v, p, version := vault, vaultPwd, vaultVersion
+	vaultMu.RUnlock()
+	if v != nil && version == current {
+		return v, p, current, true
 	}
-	v, p, err = storage.LoadVaultWithPassword(nil)
+	if p == nil {
+		enc, err := storage.IsVaultEncrypted()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return nil, nil, "", false
+		}
+		if enc {
+			// Not unlocked yet, or encrypted by another process since the plain vault was cached
+			lockSession()
+			http.Redirect(w, r, "/unlock", http.StatusFound)
+			return nil, nil, "", false
+		}
+	}
+	// First load of an unencrypted vault, or reload after an external change (same password as the session)
+	loaded, loadedPwd, err := storage.LoadVaultWithPassword(p)
 	if err != nil {
+		if p != nil {
+			// Encrypted with a different password now (e.g. after rekey): unlock again
+			lockSession()
+			http.Redirect(w, r, "/unlock", http.StatusFound)
+			return nil, nil, "", false
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return nil, nil, false
+		return nil, nil, "", false
 	}
 	vaultMu.Lock()
-	vault, vaultPwd = v, p
+	vault, vaultPwd, vaultVersion = loaded, loadedPwd, current
+	vaultMu.Unlock()
+	return loaded, loadedPwd, current, true
+}
+
+// lockSession forgets the cached vault and password.
+func lockSession() {
+	vaultMu.Lock()
+	vault = nil
+	vaultPwd = nil
+	vaultPwdStored = ""
+	vaultVersion = ""
 	vaultMu.Unlock()
-	return v, p, true
 }
 
 // Run starts the web server on 127.0.0.1:8080 (use WEB_PORT env to override port).
@@ -83,12 +127,16 @@ func Run() {
 	}
 	http.HandleFunc("/", listHandler)
 	http.HandleFunc("/api/copy", copyHandler)
+	http.HandleFunc("/api/generate", generateHandler)
+	http.HandleFunc("/api/otp", otpHandler)
 	http.HandleFunc("/logout", logoutHandler)
 	http.HandleFunc("/unlock", unlockHandler)
 	http.HandleFunc("/add", addHandler)
 	http.HandleFunc("/edit", editHandler)
 	http.HandleFunc("/delete", deleteHandler)
 	http.HandleFunc("/show", showHandler)
+	http.HandleFunc("/audit", auditHandler)
+	http.HandleFunc("/tags", tagsHandler)
 	log.Printf("go-passman web UI: http://%s\n", addr)
 	if err := http.ListenAndServe(addr, nil); err != nil {
 		log.Fatal(err)